@@ -0,0 +1,152 @@
+package backups
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+const (
+	// archiveSuffix is the file extension of backup archives
+	archiveSuffix = ".tar.gz"
+	// archiveTimeFormat is the UTC timestamp in archive names
+	archiveTimeFormat = "20060102-150405"
+)
+
+// ErrArchiveNotFound is returned for unknown or invalid archive names
+var ErrArchiveNotFound = errors.New("backup archive not found")
+
+// archiveName builds the file name of a backup, e.g. data_20261016-030000.tar.gz.
+// The timestamp never contains an underscore, so the volume is everything before the last one.
+func archiveName(volumeName string, t time.Time) string {
+	return volumeName + "_" + t.UTC().Format(archiveTimeFormat) + archiveSuffix
+}
+
+// parseArchiveName extracts the volume name and creation time from an archive file name
+func parseArchiveName(name string) (string, time.Time, bool) {
+	base, ok := strings.CutSuffix(name, archiveSuffix)
+	if !ok {
+		return "", time.Time{}, false
+	}
+	i := strings.LastIndex(base, "_")
+	if i <= 0 {
+		return "", time.Time{}, false
+	}
+	created, err := time.Parse(archiveTimeFormat, base[i+1:])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return base[:i], created, true
+}
+
+// listArchives lists the backup archives in dir, newest first
+func listArchives(dir string) ([]models.BackupArchive, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.BackupArchive{}, nil
+		}
+		return nil, err
+	}
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	archives := []models.BackupArchive{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		volumeName, created, ok := parseArchiveName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		archives = append(archives, models.BackupArchive{
+			Name:      entry.Name(),
+			Volume:    volumeName,
+			Size:      info.Size(),
+			CreatedAt: created.Unix(),
+		})
+	}
+
+	sort.Slice(archives, func(i, j int) bool {
+		if archives[i].CreatedAt != archives[j].CreatedAt {
+			return archives[i].CreatedAt > archives[j].CreatedAt
+		}
+		return archives[i].Name < archives[j].Name
+	})
+	return archives, nil
+}
+
+// archivePath validates an archive name and returns its path in dir
+func archivePath(dir, name string) (string, error) {
+	if name != filepath.Base(name) || strings.ContainsAny(name, `/\`) {
+		return "", ErrArchiveNotFound
+	}
+	if _, _, ok := parseArchiveName(name); !ok {
+		return "", ErrArchiveNotFound
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// expiredArchives selects the archives to delete: those beyond the newest keepLast of their
+// volume and those older than maxAge. The newest archive of each volume is always kept.
+func expiredArchives(archives []models.BackupArchive, keepLast int, maxAge time.Duration, now time.Time) []string {
+	expired := []string{}
+	seen := make(map[string]int)
+	// Archives are sorted newest first
+	for _, archive := range archives {
+		seen[archive.Volume]++
+		position := seen[archive.Volume]
+		if position == 1 {
+			continue
+		}
+		if keepLast > 0 && position > keepLast {
+			expired = append(expired, archive.Name)
+			continue
+		}
+		if maxAge > 0 && now.Sub(time.Unix(archive.CreatedAt, 0)) > maxAge {
+			expired = append(expired, archive.Name)
+		}
+	}
+	return expired
+}
+
+// writeArchive writes an archive to dir under name, going through a temporary file so that
+// incomplete archives never show up in listings
+func writeArchive(dir, name string, write func(*os.File) error) (int64, error) {
+	tmp, err := os.CreateTemp(dir, "."+name+".*.partial")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return 0, fmt.Errorf("failed to store archive: %w", err)
+	}
+	return info.Size(), nil
+}
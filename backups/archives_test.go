@@ -0,0 +1,90 @@
+package backups
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+func TestExpiredArchives(t *testing.T) {
+	now := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	archive := func(name, volume string, age time.Duration) models.BackupArchive {
+		return models.BackupArchive{Name: name, Volume: volume, CreatedAt: now.Add(-age).Unix()}
+	}
+
+	// Newest first, as listed
+	archives := []models.BackupArchive{
+		archive("data-1", "data", 0),
+		archive("logs-1", "logs", 1*time.Hour),
+		archive("data-2", "data", 1*day),
+		archive("data-3", "data", 2*day),
+		archive("logs-2", "logs", 10*day),
+		archive("data-4", "data", 30*day),
+	}
+
+	tests := []struct {
+		name     string
+		archives []models.BackupArchive
+		keepLast int
+		maxAge   time.Duration
+		want     []string
+	}{
+		{
+			name:     "no retention",
+			archives: archives,
+			want:     []string{},
+		},
+		{
+			name:     "keep last per volume",
+			archives: archives,
+			keepLast: 2,
+			want:     []string{"data-3", "data-4"},
+		},
+		{
+			name:     "keep last one",
+			archives: archives,
+			keepLast: 1,
+			want:     []string{"data-2", "data-3", "logs-2", "data-4"},
+		},
+		{
+			name:     "max age",
+			archives: archives,
+			maxAge:   7 * day,
+			want:     []string{"logs-2", "data-4"},
+		},
+		{
+			name:     "keep last and max age",
+			archives: archives,
+			keepLast: 3,
+			maxAge:   36 * time.Hour,
+			want:     []string{"data-3", "logs-2", "data-4"},
+		},
+		{
+			name: "newest archive is kept even when too old",
+			archives: []models.BackupArchive{
+				archive("data-1", "data", 40*day),
+				archive("data-2", "data", 50*day),
+			},
+			keepLast: 5,
+			maxAge:   7 * day,
+			want:     []string{"data-2"},
+		},
+		{
+			name:     "empty",
+			archives: nil,
+			keepLast: 1,
+			maxAge:   day,
+			want:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		got := expiredArchives(tt.archives, tt.keepLast, tt.maxAge, now)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expiredArchives() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package backups
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleMacros are the supported shorthands for common schedules
+var scheduleMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes the range of one field of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// Schedule is a parsed five-field cron expression (minute, hour, day of month, month,
+// day of week). Fields accept *, numbers, ranges (1-5), lists (1,15) and steps (*/15, 0-30/10).
+// Day of week 7 is accepted as Sunday. As in cron, when both day fields are restricted a time
+// matches if either of them does.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // Bit sets of allowed values
+	domAny, dowAny                bool
+}
+
+// ParseSchedule parses a cron expression or one of the @hourly, @daily, @weekly, @monthly
+// and @yearly macros
+func ParseSchedule(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := scheduleMacros[expr]; ok {
+		expr = macro
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("expected %d fields in cron expression %q, got %d", len(cronFields), expr, len(parts))
+	}
+
+	bits := make([]uint64, len(cronFields))
+	for i, part := range parts {
+		field := cronFields[i]
+		if i == 4 {
+			// Allow 7 for Sunday
+			field.max = 7
+		}
+		set, err := parseCronField(part, field)
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = set
+	}
+
+	// Fold Sunday as 7 into 0
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &Schedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges and steps into a bit set
+func parseCronField(value string, field cronField) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, field.name)
+			}
+			step = n
+		}
+
+		low, high := field.min, field.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseCronValue(lowPart, field); err != nil {
+				return 0, err
+			}
+			if high, err = parseCronValue(highPart, field); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangePart, field.name)
+			}
+		default:
+			n, err := parseCronValue(rangePart, field)
+			if err != nil {
+				return 0, err
+			}
+			low = n
+			if !hasStep {
+				high = n
+			}
+		}
+
+		for n := low; n <= high; n += step {
+			set |= 1 << uint(n)
+		}
+	}
+	return set, nil
+}
+
+func parseCronValue(value string, field cronField) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < field.min || n > field.max {
+		return 0, fmt.Errorf("invalid value %q in %s field (%d-%d)", value, field.name, field.min, field.max)
+	}
+	return n, nil
+}
+
+// Next returns the first time after t that matches the schedule, in t's location.
+// It returns the zero time if nothing matches within five years (e.g. "0 0 31 2 *").
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay checks the day of month and day of week fields
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,125 @@
+package backups
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@every 5m",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1- * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	}
+
+	for _, expr := range tests {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestParseScheduleSunday(t *testing.T) {
+	tests := []struct {
+		expr string
+		want uint64
+	}{
+		{"0 0 * * 0", 1},
+		{"0 0 * * 7", 1},
+		{"0 0 * * 0,7", 1},
+		{"0 0 * * 5-7", 1<<5 | 1<<6 | 1},
+		{"0 0 * * *", 1<<7 - 1},
+		{"@weekly", 1},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if schedule.dow != tt.want {
+			t.Errorf("ParseSchedule(%q) day of week = %b, want %b", tt.expr, schedule.dow, tt.want)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Saturday
+	from := time.Date(2026, 10, 17, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"*/15 * * * *", from, time.Date(2026, 10, 17, 10, 15, 0, 0, time.UTC)},
+		{"* * * * *", from, time.Date(2026, 10, 17, 10, 8, 0, 0, time.UTC)},
+		{"0,30 9-17 * * *", from, time.Date(2026, 10, 17, 10, 30, 0, 0, time.UTC)},
+		{"@hourly", from, time.Date(2026, 10, 17, 11, 0, 0, 0, time.UTC)},
+		// A matching time is never returned for itself
+		{"0 3 * * *", time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC), time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC)},
+		{"@daily", from, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 20 * *", from, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		// Month and year boundaries
+		{"@monthly", from, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", from, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"59 23 31 12 *", time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC), time.Date(2027, 12, 31, 23, 59, 0, 0, time.UTC)},
+		{"30 12 * 2 *", from, time.Date(2027, 2, 1, 12, 30, 0, 0, time.UTC)},
+		{"0 0 31 * *", from, time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2026, 10, 31, 1, 0, 0, 0, time.UTC), time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", from, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Only the day of week is restricted
+		{"0 0 * * 1", from, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", from, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", from, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 5-7", from, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matching is enough
+		{"0 0 1,15 * 1", from, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", from, time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)},
+		{"0 0 18 * 5", from, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		// Never matches
+		{"0 0 31 2 *", from, time.Time{}},
+		{"0 0 30 2 *", from, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("ParseSchedule(%q).Next(%s) = %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNextLocation(t *testing.T) {
+	location := time.FixedZone("UTC+2", 2*60*60)
+	schedule, err := ParseSchedule("0 3 * * *")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+
+	// The schedule is evaluated in the location of the given time
+	got := schedule.Next(time.Date(2026, 10, 17, 4, 0, 0, 0, location))
+	want := time.Date(2026, 10, 18, 3, 0, 0, 0, location)
+	if !got.Equal(want) || got.Location() != location {
+		t.Errorf("Next() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,416 @@
+package backups
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dev-zapi/docker-simple-panel/config"
+	"github.com/dev-zapi/docker-simple-panel/docker"
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+const (
+	// TriggerSchedule marks runs started by the cron schedule
+	TriggerSchedule = "schedule"
+	// TriggerManual marks runs started through the API
+	TriggerManual = "manual"
+
+	// historyFile stores the run history in the backup directory
+	historyFile = "history.json"
+	// maxHistory is the number of runs kept in the history
+	maxHistory = 100
+	// runTimeout bounds how long a single run may take
+	runTimeout = 6 * time.Hour
+)
+
+// ErrRunInProgress is returned when a run is requested while another one is still running
+var ErrRunInProgress = errors.New("a backup run is already in progress")
+
+// volumeNamePattern matches valid Docker volume names
+var volumeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Scheduler runs volume backups on the configured cron schedule or on demand, writes the
+// archives to the backup directory and applies the retention settings after each run
+type Scheduler struct {
+	manager       *docker.Manager
+	configManager *config.Manager
+
+	ctx    context.Context // Cancelled by Stop
+	cancel context.CancelFunc
+	done   chan struct{}
+	runs   sync.WaitGroup // Manual runs started by Trigger
+
+	mu      sync.Mutex
+	running bool
+	nextRun time.Time
+	history []models.BackupRun // Oldest first
+}
+
+// NewScheduler creates a backup scheduler and loads the run history from the backup directory
+func NewScheduler(manager *docker.Manager, configManager *config.Manager) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		manager:       manager,
+		configManager: configManager,
+		ctx:           ctx,
+		cancel:        cancel,
+		done:          make(chan struct{}),
+	}
+	s.loadHistory()
+	return s
+}
+
+// Start runs the schedule in the background. Without a schedule only manual runs are possible.
+func (s *Scheduler) Start() error {
+	expr := s.configManager.GetBackupConfig().Schedule
+	if expr == "" {
+		close(s.done)
+		return nil
+	}
+
+	schedule, err := ParseSchedule(expr)
+	if err != nil {
+		close(s.done)
+		return fmt.Errorf("invalid backup schedule: %w", err)
+	}
+
+	go func() {
+		defer close(s.done)
+		for {
+			next := schedule.Next(time.Now())
+			if next.IsZero() {
+				log.Printf("Warning: backup schedule %q never matches, scheduled backups disabled", expr)
+				return
+			}
+			s.mu.Lock()
+			s.nextRun = next
+			s.mu.Unlock()
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-s.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			id, err := newRunID()
+			if err == nil {
+				err = s.begin()
+			}
+			if err != nil {
+				log.Printf("Skipping scheduled backup: %v", err)
+				continue
+			}
+			s.run(id, TriggerSchedule, nil)
+		}
+	}()
+	return nil
+}
+
+// Stop cancels the schedule and any running backup, and waits for the scheduler and manual
+// runs to exit so no run is abandoned halfway
+func (s *Scheduler) Stop() {
+	s.cancel()
+	<-s.done
+	s.runs.Wait()
+}
+
+// Trigger starts a backup run in the background. Without volume names, the configured targets
+// are backed up. It returns the ID the run will have in the history.
+func (s *Scheduler) Trigger(volumes []string) (string, error) {
+	for _, name := range volumes {
+		if !volumeNamePattern.MatchString(name) {
+			return "", fmt.Errorf("invalid volume name %q", name)
+		}
+	}
+
+	id, err := newRunID()
+	if err != nil {
+		return "", err
+	}
+	if err := s.begin(); err != nil {
+		return "", err
+	}
+
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		s.run(id, TriggerManual, volumes)
+	}()
+	return id, nil
+}
+
+// begin marks a run as in progress
+func (s *Scheduler) begin() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return ErrRunInProgress
+	}
+	s.running = true
+	return nil
+}
+
+// run backs up the volumes, applies retention and records the run; begin must have been called
+func (s *Scheduler) run(id, trigger string, volumes []string) {
+	ctx, cancel := context.WithTimeout(s.ctx, runTimeout)
+	defer cancel()
+
+	cfg := s.configManager.GetBackupConfig()
+	run := models.BackupRun{
+		ID:        id,
+		Trigger:   trigger,
+		StartedAt: time.Now().Unix(),
+		Volumes:   []models.BackupVolumeResult{},
+		Deleted:   []string{},
+	}
+	log.Printf("Backup run %s (%s) started", id, trigger)
+
+	if err := s.backupVolumes(ctx, cfg, volumes, &run); err != nil {
+		run.Error = err.Error()
+	}
+	run.Success = run.Error == ""
+	for _, result := range run.Volumes {
+		if result.Error != "" {
+			run.Success = false
+		}
+	}
+
+	if deleted, err := s.applyRetention(cfg, time.Now()); err != nil {
+		log.Printf("Warning: failed to apply backup retention: %v", err)
+	} else {
+		run.Deleted = deleted
+	}
+
+	run.FinishedAt = time.Now().Unix()
+	log.Printf("Backup run %s finished: %d volume(s), success=%t", id, len(run.Volumes), run.Success)
+
+	s.mu.Lock()
+	s.running = false
+	s.history = append(s.history, run)
+	if len(s.history) > maxHistory {
+		s.history = s.history[len(s.history)-maxHistory:]
+	}
+	s.mu.Unlock()
+	s.saveHistory(cfg.Dir)
+}
+
+// backupVolumes writes one archive per volume, recording the outcome of each in the run
+func (s *Scheduler) backupVolumes(ctx context.Context, cfg config.BackupConfig, volumes []string, run *models.BackupRun) error {
+	if len(volumes) == 0 {
+		var err error
+		if volumes, err = s.resolveTargets(ctx, cfg); err != nil {
+			return fmt.Errorf("failed to resolve backup targets: %w", err)
+		}
+		if len(volumes) == 0 {
+			return errors.New("no volumes match the backup configuration")
+		}
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0750); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	explorerImage := s.configManager.GetVolumeExplorerImage()
+	for _, volumeName := range volumes {
+		result := models.BackupVolumeResult{Volume: volumeName}
+		name := archiveName(volumeName, time.Now())
+		size, err := writeArchive(cfg.Dir, name, func(f *os.File) error {
+			archive, err := s.manager.BackupVolume(ctx, volumeName, explorerImage)
+			if err != nil {
+				return err
+			}
+			defer archive.Close()
+			_, err = io.Copy(f, archive)
+			return err
+		})
+		if err != nil {
+			log.Printf("Backup of volume %s failed: %v", volumeName, err)
+			result.Error = err.Error()
+		} else {
+			result.Archive = name
+			result.Size = size
+		}
+		run.Volumes = append(run.Volumes, result)
+	}
+	return nil
+}
+
+// resolveTargets lists the configured volumes and the volumes matching the configured labels
+func (s *Scheduler) resolveTargets(ctx context.Context, cfg config.BackupConfig) ([]string, error) {
+	targets := make(map[string]bool)
+	for _, name := range cfg.Volumes {
+		// Missing volumes are reported as failures of the run
+		targets[name] = true
+	}
+
+	if len(cfg.Labels) > 0 {
+		volumes, err := s.manager.ListVolumes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, volume := range volumes {
+			if matchesAnyLabel(volume.Labels, cfg.Labels) {
+				targets[volume.Name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// matchesAnyLabel reports whether labels satisfy one of the "key" or "key=value" selectors
+func matchesAnyLabel(labels map[string]string, selectors []string) bool {
+	for _, selector := range selectors {
+		key, value, hasValue := strings.Cut(selector, "=")
+		actual, ok := labels[key]
+		if ok && (!hasValue || actual == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRetention deletes expired archives and returns their names
+func (s *Scheduler) applyRetention(cfg config.BackupConfig, now time.Time) ([]string, error) {
+	if cfg.KeepLast <= 0 && cfg.MaxAgeDays <= 0 {
+		return []string{}, nil
+	}
+
+	archives, err := listArchives(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := []string{}
+	maxAge := time.Duration(cfg.MaxAgeDays) * 24 * time.Hour
+	for _, name := range expiredArchives(archives, cfg.KeepLast, maxAge, now) {
+		if err := os.Remove(filepath.Join(cfg.Dir, name)); err != nil {
+			log.Printf("Warning: failed to delete expired backup %s: %v", name, err)
+			continue
+		}
+		deleted = append(deleted, name)
+	}
+	return deleted, nil
+}
+
+// Status returns the backup settings, the scheduler state and the stored archives
+func (s *Scheduler) Status() (*models.BackupStatus, error) {
+	cfg := s.configManager.GetBackupConfig()
+	archives, err := listArchives(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := &models.BackupStatus{
+		Schedule: cfg.Schedule,
+		Running:  s.running,
+		Dir:      cfg.Dir,
+		Archives: archives,
+	}
+	if !s.nextRun.IsZero() {
+		status.NextRun = s.nextRun.Unix()
+	}
+	return status, nil
+}
+
+// History returns the recorded runs, newest first
+func (s *Scheduler) History() []models.BackupRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := make([]models.BackupRun, 0, len(s.history))
+	for i := len(s.history) - 1; i >= 0; i-- {
+		history = append(history, s.history[i])
+	}
+	return history
+}
+
+// OpenArchive opens a stored archive for download
+func (s *Scheduler) OpenArchive(name string) (*os.File, error) {
+	path, err := archivePath(s.configManager.GetBackupConfig().Dir, name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrArchiveNotFound
+	}
+	return f, err
+}
+
+// DeleteArchive deletes a stored archive
+func (s *Scheduler) DeleteArchive(name string) error {
+	path, err := archivePath(s.configManager.GetBackupConfig().Dir, name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return ErrArchiveNotFound
+	}
+	return err
+}
+
+// loadHistory reads the run history saved by a previous process
+func (s *Scheduler) loadHistory() {
+	data, err := os.ReadFile(filepath.Join(s.configManager.GetBackupConfig().Dir, historyFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: failed to read backup history: %v", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &s.history); err != nil {
+		log.Printf("Warning: failed to parse backup history: %v", err)
+	}
+}
+
+// saveHistory writes the run history to the backup directory
+func (s *Scheduler) saveHistory(dir string) {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.history, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("Warning: failed to encode backup history: %v", err)
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		log.Printf("Warning: failed to save backup history: %v", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(dir, historyFile), data, 0600); err != nil {
+		log.Printf("Warning: failed to save backup history: %v", err)
+	}
+}
+
+// newRunID generates a random run identifier
+func newRunID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
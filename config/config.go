@@ -75,6 +75,27 @@ type ServerConfig struct {
 type DockerConfig struct {
 	Socket             string `yaml:"socket"`
 	VolumeExplorerImage string `yaml:"volume_explorer_image"`
+	StopTimeout        int    `yaml:"stop_timeout"` // Seconds to wait before killing a container on stop/restart
+	StacksDir          string `yaml:"stacks_dir"`   // Directory where stack compose files are stored
+	StacksHostDir      string `yaml:"stacks_host_dir"` // Path of StacksDir on the Docker host, if it differs
+}
+
+// RegistryConfig holds credentials and connection settings for a container registry
+type RegistryConfig struct {
+	Host     string `yaml:"host"`     // Registry host, e.g. ghcr.io or registry.example.com:5000 ("docker.io" for Docker Hub)
+	Username string `yaml:"username"`
+	Password string `yaml:"password"` // Password or access token
+	Insecure bool   `yaml:"insecure"` // Use plain HTTP instead of HTTPS
+}
+
+// BackupConfig holds the settings of scheduled volume backups
+type BackupConfig struct {
+	Schedule   string   `yaml:"schedule"`     // Cron expression, e.g. "0 3 * * *"; empty disables scheduled runs
+	Volumes    []string `yaml:"volumes"`      // Names of volumes to back up
+	Labels     []string `yaml:"labels"`       // Back up volumes with any of these labels ("key" or "key=value")
+	Dir        string   `yaml:"dir"`          // Directory where archives are written
+	KeepLast   int      `yaml:"keep_last"`    // Archives kept per volume, 0 keeps all
+	MaxAgeDays int      `yaml:"max_age_days"` // Archives older than this are deleted, 0 keeps all
 }
 
 // LoggingConfig holds logging configuration
@@ -89,6 +110,8 @@ type Config struct {
 	Server     ServerConfig   `yaml:"server"`
 	Docker     DockerConfig   `yaml:"docker"`
 	Logging    LoggingConfig  `yaml:"logging"`
+	Registries []RegistryConfig `yaml:"registries,omitempty"`
+	Backups    BackupConfig   `yaml:"backups"`
 	StaticPath string         `yaml:"static_path"`
 	
 	// Runtime fields (not persisted)
@@ -99,6 +122,15 @@ type Config struct {
 
 const defaultConfigPath = "./config.yaml"
 
+// DefaultStopTimeout is the default number of seconds to wait for a container to stop
+const DefaultStopTimeout = 10
+
+// DefaultStacksDir is the default directory for stack compose files
+const DefaultStacksDir = "./stacks"
+
+// DefaultBackupsDir is the default directory for volume backup archives
+const DefaultBackupsDir = "./backups"
+
 // LoadConfig loads configuration from YAML file
 func LoadConfig() (*Config, error) {
 	configPath := getEnv("CONFIG_PATH", defaultConfigPath)
@@ -138,6 +170,17 @@ func LoadConfig() (*Config, error) {
 	
 	cfg.configPath = configPath
 	
+	// Fall back to the default stop timeout for configs written before it existed
+	if cfg.Docker.StopTimeout <= 0 {
+		cfg.Docker.StopTimeout = DefaultStopTimeout
+	}
+	if cfg.Docker.StacksDir == "" {
+		cfg.Docker.StacksDir = DefaultStacksDir
+	}
+	if cfg.Backups.Dir == "" {
+		cfg.Backups.Dir = DefaultBackupsDir
+	}
+	
 	// If static path is blank in config, try to read from environment variable
 	if cfg.StaticPath == "" {
 		cfg.StaticPath = os.Getenv("STATIC_PATH")
@@ -243,10 +286,15 @@ func getDefaultConfig() *Config {
 		Docker: DockerConfig{
 			Socket:             "/var/run/docker.sock",
 			VolumeExplorerImage: "ghcr.io/dev-zapi/docker-simple-panel:latest",
+			StopTimeout:        DefaultStopTimeout,
+			StacksDir:          DefaultStacksDir,
 		},
 		Logging: LoggingConfig{
 			Level: "info",
 		},
+		Backups: BackupConfig{
+			Dir: DefaultBackupsDir,
+		},
 		StaticPath: "",
 	}
 }
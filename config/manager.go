@@ -1,6 +1,8 @@
 package config
 
 import (
+	"fmt"
+	"path/filepath"
 	"sync"
 )
 
@@ -80,6 +82,70 @@ func (m *Manager) SetVolumeExplorerImage(image string) error {
 	return m.config.Save()
 }
 
+// GetStopTimeout returns the default container stop timeout in seconds
+func (m *Manager) GetStopTimeout() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.Docker.StopTimeout
+}
+
+// SetStopTimeout updates the default container stop timeout in seconds
+func (m *Manager) SetStopTimeout(timeout int) error {
+	if timeout <= 0 {
+		return fmt.Errorf("stop timeout must be a positive number of seconds")
+	}
+
+	m.mu.Lock()
+	m.config.Docker.StopTimeout = timeout
+	m.mu.Unlock()
+
+	// Save to config file
+	return m.config.Save()
+}
+
+// GetStacksDir returns the directory where stack compose files are stored
+func (m *Manager) GetStacksDir() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.Docker.StacksDir
+}
+
+// SetStacksDir updates the directory where stack compose files are stored
+func (m *Manager) SetStacksDir(dir string) error {
+	if dir == "" {
+		return fmt.Errorf("stacks directory cannot be empty")
+	}
+
+	m.mu.Lock()
+	m.config.Docker.StacksDir = dir
+	m.mu.Unlock()
+
+	// Save to config file
+	return m.config.Save()
+}
+
+// GetStacksHostDir returns the path of the stacks directory on the Docker host, or "" if unset
+func (m *Manager) GetStacksHostDir() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.Docker.StacksHostDir
+}
+
+// SetStacksHostDir updates the path of the stacks directory on the Docker host.
+// An empty path clears the setting.
+func (m *Manager) SetStacksHostDir(dir string) error {
+	if dir != "" && !filepath.IsAbs(dir) {
+		return fmt.Errorf("stacks host directory must be an absolute path")
+	}
+
+	m.mu.Lock()
+	m.config.Docker.StacksHostDir = dir
+	m.mu.Unlock()
+
+	// Save to config file
+	return m.config.Save()
+}
+
 // SetDockerSocketChangeCallback sets the callback for Docker socket changes
 func (m *Manager) SetDockerSocketChangeCallback(callback func(string) error) {
 	m.mu.Lock()
@@ -116,11 +182,31 @@ func (m *Manager) ValidateCredentials(username, password string) error {
 	return m.config.ValidateCredentials(username, password)
 }
 
+// GetRegistries returns the configured registry credentials
+func (m *Manager) GetRegistries() []RegistryConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]RegistryConfig{}, m.config.Registries...)
+}
+
+// GetBackupConfig returns the scheduled backup settings
+func (m *Manager) GetBackupConfig() BackupConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cfg := m.config.Backups
+	cfg.Volumes = append([]string{}, cfg.Volumes...)
+	cfg.Labels = append([]string{}, cfg.Labels...)
+	return cfg
+}
+
 // SystemConfig represents the system configuration
 type SystemConfig struct {
 	DockerSocket        string `json:"docker_socket"`
 	LogLevel            string `json:"log_level"`
 	VolumeExplorerImage string `json:"volume_explorer_image"`
+	StopTimeout         int    `json:"stop_timeout"`
+	StacksDir           string `json:"stacks_dir"`
+	StacksHostDir       string `json:"stacks_host_dir"`
 	SessionMaxTimeout   int    `json:"session_max_timeout"`
 	Username            string `json:"username"`
 }
@@ -133,6 +219,9 @@ func (m *Manager) GetSystemConfig() SystemConfig {
 		DockerSocket:        m.config.Docker.Socket,
 		LogLevel:            m.config.Logging.Level,
 		VolumeExplorerImage: m.config.Docker.VolumeExplorerImage,
+		StopTimeout:         m.config.Docker.StopTimeout,
+		StacksDir:           m.config.Docker.StacksDir,
+		StacksHostDir:       m.config.Docker.StacksHostDir,
 		SessionMaxTimeout:   m.config.Server.SessionMaxTimeout,
 		Username:            m.config.Username,
 	}
@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// BuildOptions holds the settings of an image build
+type BuildOptions struct {
+	Tags       []string
+	Target     string             // Multi-stage build target
+	Dockerfile string             // Path of the Dockerfile within the context, defaults to "Dockerfile"
+	BuildArgs  map[string]*string // Build-time variables; a nil value takes the variable from the environment
+	NoCache    bool
+	Pull       bool // Always attempt to pull newer versions of base images
+}
+
+// BuildImage builds an image from a tar build context (optionally gzip-compressed) and
+// reports the build output line by line through onLine. It returns the ID of the built image.
+func (c *Client) BuildImage(ctx context.Context, buildContext io.Reader, opts BuildOptions, onLine func(string) error) (string, error) {
+	resp, err := c.cli.ImageBuild(ctx, buildContext, types.ImageBuildOptions{
+		Tags:        opts.Tags,
+		Target:      opts.Target,
+		Dockerfile:  opts.Dockerfile,
+		BuildArgs:   opts.BuildArgs,
+		NoCache:     opts.NoCache,
+		PullParent:  opts.Pull,
+		Remove:      true,
+		ForceRemove: true,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	imageID := ""
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", fmt.Errorf("failed to read build output: %w", err)
+		}
+		if msg.Error != nil {
+			return "", msg.Error
+		}
+
+		// The final image ID is reported as an auxiliary message
+		if msg.Aux != nil {
+			var result types.BuildResult
+			if err := json.Unmarshal(*msg.Aux, &result); err == nil && result.ID != "" {
+				imageID = result.ID
+			}
+			continue
+		}
+
+		text := msg.Stream
+		if text == "" && msg.Status != "" {
+			text = msg.Status
+			if msg.ID != "" {
+				text = msg.ID + ": " + text
+			}
+			if msg.Progress != nil && msg.Progress.Total > 0 {
+				text += fmt.Sprintf(" %d/%d", msg.Progress.Current, msg.Progress.Total)
+			}
+		}
+		for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			if err := onLine(line); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if imageID == "" {
+		return "", errors.New("build finished without reporting an image ID")
+	}
+	return imageID, nil
+}
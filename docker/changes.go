@@ -0,0 +1,178 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+const (
+	// ChangeAdded marks paths created in the container
+	ChangeAdded = "added"
+	// ChangeModified marks paths of the image changed in the container
+	ChangeModified = "modified"
+	// ChangeDeleted marks paths of the image deleted in the container
+	ChangeDeleted = "deleted"
+)
+
+const (
+	// maxConcurrentSizeLookups bounds the number of parallel stat requests made for change sizes
+	maxConcurrentSizeLookups = 8
+	// maxSizeLookups is the number of added and modified paths above which sizes are not looked
+	// up, as every path takes a request to the daemon
+	maxSizeLookups = 2000
+)
+
+// changeKind converts a change type of the Docker API
+func changeKind(kind container.ChangeType) string {
+	switch kind {
+	case container.ChangeAdd:
+		return ChangeAdded
+	case container.ChangeDelete:
+		return ChangeDeleted
+	default:
+		return ChangeModified
+	}
+}
+
+// ContainerChanges lists the paths changed in a container's writable layer as a tree, like
+// `docker diff`. With sizes, the sizes of added and modified files are looked up and summed up
+// for directories, and the size of the writable layer is reported.
+func (c *Client) ContainerChanges(ctx context.Context, containerID string, sizes bool) (*models.ContainerChanges, error) {
+	changes, err := c.cli.ContainerDiff(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ContainerChanges{
+		Root: &models.ContainerChangeNode{Name: "/", Path: "/", IsDirectory: true},
+	}
+	nodes := map[string]*models.ContainerChangeNode{"/": result.Root}
+
+	// node returns the node of a path, adding it and its parents to the tree if needed
+	var node func(p string) *models.ContainerChangeNode
+	node = func(p string) *models.ContainerChangeNode {
+		if n, ok := nodes[p]; ok {
+			return n
+		}
+		parent := node(path.Dir(p))
+		parent.IsDirectory = true
+		n := &models.ContainerChangeNode{Name: path.Base(p), Path: p}
+		parent.Children = append(parent.Children, n)
+		nodes[p] = n
+		return n
+	}
+
+	for _, change := range changes {
+		n := node(path.Clean("/" + change.Path))
+		n.Kind = changeKind(change.Kind)
+		switch n.Kind {
+		case ChangeAdded:
+			result.Added++
+		case ChangeDeleted:
+			result.Deleted++
+		default:
+			result.Modified++
+		}
+	}
+
+	if sizes {
+		if err := c.fillChangeSizes(ctx, containerID, result, nodes); err != nil {
+			return nil, err
+		}
+		if inspect, _, err := c.cli.ContainerInspectWithRaw(ctx, containerID, true); err == nil {
+			result.SizeRw = inspect.SizeRw
+		}
+	}
+
+	sortChangeTree(result.Root)
+	return result, nil
+}
+
+// fillChangeSizes looks up the sizes of added and modified paths, marks directories and sums up
+// the sizes of files for their parent directories. Paths that cannot be looked up are skipped;
+// with more than maxSizeLookups paths, none are looked up and result.SizesOmitted is set. It
+// fails if ctx ends before all lookups are done.
+func (c *Client) fillChangeSizes(ctx context.Context, containerID string, result *models.ContainerChanges, nodes map[string]*models.ContainerChangeNode) error {
+	var paths []string
+	for p, n := range nodes {
+		if n.Kind == ChangeAdded || n.Kind == ChangeModified {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) > maxSizeLookups {
+		result.SizesOmitted = true
+		return nil
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	fileSizes := make(map[string]int64)
+	jobs := make(chan string)
+	for i := 0; i < min(maxConcurrentSizeLookups, len(paths)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				stat, err := c.cli.ContainerStatPath(ctx, containerID, p)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				if stat.Mode.IsDir() {
+					nodes[p].IsDirectory = true
+				} else if stat.Mode&os.ModeSymlink == 0 {
+					fileSizes[p] = stat.Size
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for _, p := range paths {
+		select {
+		case jobs <- p:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Directories report the total of the files below them
+	for p, size := range fileSizes {
+		for {
+			n := nodes[p]
+			total := size
+			if n.Size != nil {
+				total += *n.Size
+			}
+			n.Size = &total
+			if p == "/" {
+				break
+			}
+			p = path.Dir(p)
+		}
+	}
+	return nil
+}
+
+// sortChangeTree orders the children of every node by name
+func sortChangeTree(n *models.ContainerChangeNode) {
+	sort.Slice(n.Children, func(i, j int) bool {
+		return strings.Compare(n.Children[i].Name, n.Children[j].Name) < 0
+	})
+	for _, child := range n.Children {
+		sortChangeTree(child)
+	}
+}
@@ -1,7 +1,6 @@
 package docker
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"io"
@@ -9,13 +8,13 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
-	"github.com/docker/docker/pkg/stdcopy"
 
 	"github.com/dev-zapi/docker-simple-panel/models"
 )
@@ -23,11 +22,17 @@ import (
 const (
 	// shortIDLength is the length of the short container ID (12 hex characters)
 	shortIDLength = 12
+
+	// StopTimeoutLabel is the container label that overrides the default stop timeout (in seconds)
+	StopTimeoutLabel = "dsp.stop-timeout"
 )
 
 // Client wraps the Docker client
 type Client struct {
 	cli *client.Client
+
+	// inFlight counts the operations that use the client without holding the manager lock
+	inFlight sync.WaitGroup
 }
 
 // NewClient creates a new Docker client using the Unix socket
@@ -53,6 +58,10 @@ func (c *Client) ListContainers(ctx context.Context) ([]models.ContainerInfo, er
 	// Initialize as empty slice to ensure JSON marshals to [] instead of null
 	result := []models.ContainerInfo{}
 	for _, container := range containers {
+		if isVolumeHelper(container) {
+			continue
+		}
+
 		// Get health status
 		health := "none"
 		if container.State == "running" {
@@ -75,10 +84,10 @@ func (c *Client) ListContainers(ctx context.Context) ([]models.ContainerInfo, er
 		composeProject := ""
 		composeService := ""
 		if container.Labels != nil {
-			if project, ok := container.Labels["com.docker.compose.project"]; ok {
+			if project, ok := container.Labels[composeProjectLabel]; ok {
 				composeProject = project
 			}
-			if service, ok := container.Labels["com.docker.compose.service"]; ok {
+			if service, ok := container.Labels[composeServiceLabel]; ok {
 				composeService = service
 			}
 		}
@@ -95,6 +104,7 @@ func (c *Client) ListContainers(ctx context.Context) ([]models.ContainerInfo, er
 			ID:             container.ID[:shortIDLength],
 			Name:           name,
 			Image:          container.Image,
+			ImageID:        shortImageID(container.ImageID),
 			State:          container.State,
 			Status:         container.Status,
 			Health:         health,
@@ -113,24 +123,82 @@ func (c *Client) StartContainer(ctx context.Context, containerID string) error {
 	return c.cli.ContainerStart(ctx, containerID, types.ContainerStartOptions{})
 }
 
-// StopContainer stops a container
-func (c *Client) StopContainer(ctx context.Context, containerID string) error {
-	timeout := 10
+// StopContainer stops a container, waiting timeout seconds before killing it
+func (c *Client) StopContainer(ctx context.Context, containerID string, timeout int) error {
 	stopOptions := container.StopOptions{
 		Timeout: &timeout,
 	}
 	return c.cli.ContainerStop(ctx, containerID, stopOptions)
 }
 
-// RestartContainer restarts a container
-func (c *Client) RestartContainer(ctx context.Context, containerID string) error {
-	timeout := 10
+// RestartContainer restarts a container, waiting timeout seconds before killing it
+func (c *Client) RestartContainer(ctx context.Context, containerID string, timeout int) error {
 	stopOptions := container.StopOptions{
 		Timeout: &timeout,
 	}
 	return c.cli.ContainerRestart(ctx, containerID, stopOptions)
 }
 
+// ResolveStopTimeout determines the stop timeout for a container in seconds.
+// Precedence: the requested timeout, the StopTimeoutLabel label, the container's own
+// stop timeout (docker run --stop-timeout), and finally defaultTimeout.
+func (c *Client) ResolveStopTimeout(ctx context.Context, containerID string, requested *int, defaultTimeout int) int {
+	if requested != nil {
+		return *requested
+	}
+
+	inspect, err := c.cli.ContainerInspect(ctx, containerID)
+	if err != nil || inspect.Config == nil {
+		return defaultTimeout
+	}
+
+	if value, ok := inspect.Config.Labels[StopTimeoutLabel]; ok {
+		if timeout, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && timeout >= 0 {
+			return timeout
+		}
+		log.Printf("Warning: ignoring invalid %s label %q on container %s", StopTimeoutLabel, value, containerID)
+	}
+
+	if inspect.Config.StopTimeout != nil {
+		return *inspect.Config.StopTimeout
+	}
+
+	return defaultTimeout
+}
+
+// PauseContainer pauses all processes in a container
+func (c *Client) PauseContainer(ctx context.Context, containerID string) error {
+	return c.cli.ContainerPause(ctx, containerID)
+}
+
+// UnpauseContainer resumes all processes in a paused container
+func (c *Client) UnpauseContainer(ctx context.Context, containerID string) error {
+	return c.cli.ContainerUnpause(ctx, containerID)
+}
+
+// KillContainer sends a signal to the main process of a container
+func (c *Client) KillContainer(ctx context.Context, containerID, signal string) error {
+	return c.cli.ContainerKill(ctx, containerID, signal)
+}
+
+// RemoveContainer removes a container, optionally forcing removal of a running
+// container and removing its anonymous volumes
+func (c *Client) RemoveContainer(ctx context.Context, containerID string, force, removeVolumes bool) error {
+	return c.cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{
+		Force:         force,
+		RemoveVolumes: removeVolumes,
+	})
+}
+
+// ResolveContainerID resolves a container name or ID prefix to the full container ID
+func (c *Client) ResolveContainerID(ctx context.Context, containerID string) (string, error) {
+	inspect, err := c.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return "", err
+	}
+	return inspect.ID, nil
+}
+
 // Ping checks if the Docker daemon is accessible
 func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.cli.Ping(ctx)
@@ -166,10 +234,10 @@ func (c *Client) GetContainerInfo(ctx context.Context, containerID string) (*mod
 	composeProject := ""
 	composeService := ""
 	if inspect.Config.Labels != nil {
-		if project, ok := inspect.Config.Labels["com.docker.compose.project"]; ok {
+		if project, ok := inspect.Config.Labels[composeProjectLabel]; ok {
 			composeProject = project
 		}
-		if service, ok := inspect.Config.Labels["com.docker.compose.service"]; ok {
+		if service, ok := inspect.Config.Labels[composeServiceLabel]; ok {
 			composeService = service
 		}
 	}
@@ -195,11 +263,20 @@ func (c *Client) GetContainerInfo(ctx context.Context, containerID string) (*mod
 	networks := make(map[string]models.NetworkInfo)
 	if inspect.NetworkSettings != nil && inspect.NetworkSettings.Networks != nil {
 		for netName, netConfig := range inspect.NetworkSettings.Networks {
+			var aliases []string
+			for _, alias := range netConfig.Aliases {
+				// Docker adds the short container ID as an alias on user-defined networks
+				if alias != inspect.ID[:shortIDLength] {
+					aliases = append(aliases, alias)
+				}
+			}
 			networks[netName] = models.NetworkInfo{
-				NetworkID:  netConfig.NetworkID,
-				Gateway:    netConfig.Gateway,
-				IPAddress:  netConfig.IPAddress,
-				MacAddress: netConfig.MacAddress,
+				NetworkID:   netConfig.NetworkID,
+				Gateway:     netConfig.Gateway,
+				IPAddress:   netConfig.IPAddress,
+				IPv6Address: netConfig.GlobalIPv6Address,
+				MacAddress:  netConfig.MacAddress,
+				Aliases:     aliases,
 			}
 		}
 	}
@@ -245,6 +322,7 @@ func (c *Client) GetContainerInfo(ctx context.Context, containerID string) (*mod
 		ID:             inspect.ID[:shortIDLength],
 		Name:           name,
 		Image:          inspect.Config.Image,
+		ImageID:        shortImageID(inspect.Image),
 		State:          inspect.State.Status,
 		Status:         inspect.State.Status,
 		Health:         health,
@@ -261,22 +339,20 @@ func (c *Client) GetContainerInfo(ctx context.Context, containerID string) (*mod
 	}, nil
 }
 
-// ListVolumes lists all Docker volumes with associated container information
-func (c *Client) ListVolumes(ctx context.Context) ([]models.VolumeInfo, error) {
-	volumes, err := c.cli.VolumeList(ctx, volume.ListOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	// Get all containers to build volume-to-container mapping
+// volumeContainers builds a map of volume name to the short IDs of the containers mounting it,
+// including stopped containers
+func (c *Client) volumeContainers(ctx context.Context) (map[string][]string, error) {
 	containers, err := c.cli.ContainerList(ctx, types.ContainerListOptions{All: true})
 	if err != nil {
 		return nil, err
 	}
 
-	// Build a map of volume name to container IDs
 	volumeToContainers := make(map[string][]string)
 	for _, container := range containers {
+		// Helper containers of this application do not count as users of a volume
+		if isVolumeHelper(container) {
+			continue
+		}
 		inspect, err := c.cli.ContainerInspect(ctx, container.ID)
 		if err != nil {
 			log.Printf("Warning: failed to inspect container %s for volume mapping: %v", container.ID[:shortIDLength], err)
@@ -290,6 +366,20 @@ func (c *Client) ListVolumes(ctx context.Context) ([]models.VolumeInfo, error) {
 			}
 		}
 	}
+	return volumeToContainers, nil
+}
+
+// ListVolumes lists all Docker volumes with associated container information
+func (c *Client) ListVolumes(ctx context.Context) ([]models.VolumeInfo, error) {
+	volumes, err := c.cli.VolumeList(ctx, volume.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	volumeToContainers, err := c.volumeContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize as empty slice to ensure JSON marshals to [] instead of null
 	result := []models.VolumeInfo{}
@@ -305,6 +395,8 @@ func (c *Client) ListVolumes(ctx context.Context) ([]models.VolumeInfo, error) {
 			Mountpoint: volume.Mountpoint,
 			CreatedAt:  volume.CreatedAt,
 			Scope:      volume.Scope,
+			Labels:     volume.Labels,
+			Options:    volume.Options,
 			Containers: containers,
 		})
 	}
@@ -364,214 +456,6 @@ func (c *Client) ContainerLogs(ctx context.Context, containerID string, follow b
 	return c.cli.ContainerLogs(ctx, containerID, options)
 }
 
-// ExploreVolumeFiles lists files and directories in a volume path using a temporary container
-func (c *Client) ExploreVolumeFiles(ctx context.Context, volumeName, path, explorerImage string) ([]models.VolumeFileInfo, error) {
-	// Create a temporary container with the volume mounted
-	containerName := fmt.Sprintf("volume-explorer-%s-%d", volumeName, time.Now().Unix())
-	
-	// Create container config
-	config := &container.Config{
-		Image: explorerImage,
-		Cmd:   []string{"ls", "-la", "--full-time", "/volume" + path},
-	}
-	
-	hostConfig := &container.HostConfig{
-		Binds: []string{volumeName + ":/volume:ro"}, // Mount as read-only
-	}
-	
-	// Create the container
-	resp, err := c.cli.ContainerCreate(ctx, config, hostConfig, nil, nil, containerName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temporary container: %w", err)
-	}
-	
-	// Ensure container is removed on exit
-	defer func() {
-		removeCtx := context.Background()
-		c.cli.ContainerRemove(removeCtx, resp.ID, types.ContainerRemoveOptions{Force: true})
-	}()
-	
-	// Start the container
-	if err := c.cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
-		return nil, fmt.Errorf("failed to start temporary container: %w", err)
-	}
-	
-	// Wait for container to finish
-	statusCh, errCh := c.cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
-	select {
-	case err := <-errCh:
-		if err != nil {
-			return nil, fmt.Errorf("error waiting for container: %w", err)
-		}
-	case <-statusCh:
-	}
-	
-	// Get container logs (which contains the ls output)
-	logReader, err := c.cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get container logs: %w", err)
-	}
-	defer logReader.Close()
-	
-	// Use stdcopy to properly demux Docker streams
-	var stdout, stderr strings.Builder
-	if _, err := stdcopy.StdCopy(&stdout, &stderr, logReader); err != nil && err != io.EOF {
-		return nil, fmt.Errorf("failed to read container output: %w", err)
-	}
-	
-	// Check for errors in stderr
-	if stderr.Len() > 0 {
-		return nil, fmt.Errorf("command failed: %s", stderr.String())
-	}
-	
-	// Read and parse the output
-	// Initialize as empty slice to ensure JSON marshals to [] instead of null
-	files := []models.VolumeFileInfo{}
-	scanner := bufio.NewScanner(strings.NewReader(stdout.String()))
-	
-	// Skip the first line (total)
-	firstLine := true
-	for scanner.Scan() {
-		line := scanner.Text()
-		
-		if firstLine {
-			firstLine = false
-			if strings.HasPrefix(line, "total") {
-				continue
-			}
-		}
-		
-		// Parse ls -la output
-		// Expected format: mode links owner group size date time timezone filename
-		// Example: -rw-r--r-- 1 root root 12 2025-12-07 14:51:49.123456789 +0000 test.txt
-		const minFieldCount = 9
-		fields := strings.Fields(line)
-		if len(fields) < minFieldCount {
-			continue
-		}
-		
-		mode := fields[0]
-		sizeStr := fields[4]
-		dateTime := strings.Join(fields[5:8], " ")
-		name := strings.Join(fields[8:], " ")
-		
-		// Skip . and ..
-		if name == "." || name == ".." {
-			continue
-		}
-		
-		isDir := strings.HasPrefix(mode, "d")
-		size := int64(0)
-		if !isDir {
-			if s, err := strconv.ParseInt(sizeStr, 10, 64); err == nil {
-				size = s
-			}
-		}
-		
-		// Build full path
-		fullPath := path
-		if fullPath != "/" && !strings.HasSuffix(fullPath, "/") {
-			fullPath += "/"
-		}
-		if fullPath == "/" {
-			fullPath = "/" + name
-		} else {
-			fullPath += name
-		}
-		
-		files = append(files, models.VolumeFileInfo{
-			Name:        name,
-			Path:        fullPath,
-			IsDirectory: isDir,
-			Size:        size,
-			Mode:        mode,
-			ModTime:     dateTime,
-		})
-	}
-	
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading container output: %w", err)
-	}
-	
-	return files, nil
-}
-
-// ReadVolumeFile reads the content of a file in a volume using a temporary container
-func (c *Client) ReadVolumeFile(ctx context.Context, volumeName, filePath, explorerImage string) (*models.VolumeFileContent, error) {
-	// Create a temporary container with the volume mounted
-	containerName := fmt.Sprintf("volume-reader-%s-%d", volumeName, time.Now().Unix())
-	
-	// Create container config to read the file
-	config := &container.Config{
-		Image: explorerImage,
-		Cmd:   []string{"cat", "/volume" + filePath},
-	}
-	
-	hostConfig := &container.HostConfig{
-		Binds: []string{volumeName + ":/volume:ro"}, // Mount as read-only
-	}
-	
-	// Create the container
-	resp, err := c.cli.ContainerCreate(ctx, config, hostConfig, nil, nil, containerName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temporary container: %w", err)
-	}
-	
-	// Ensure container is removed on exit with timeout
-	defer func() {
-		removeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		c.cli.ContainerRemove(removeCtx, resp.ID, types.ContainerRemoveOptions{Force: true})
-	}()
-	
-	// Start the container
-	if err := c.cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
-		return nil, fmt.Errorf("failed to start temporary container: %w", err)
-	}
-	
-	// Wait for container to finish
-	statusCh, errCh := c.cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
-	select {
-	case err := <-errCh:
-		if err != nil {
-			return nil, fmt.Errorf("error waiting for container: %w", err)
-		}
-	case <-statusCh:
-	}
-	
-	// Get container logs (which contains the file content)
-	logReader, err := c.cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get container logs: %w", err)
-	}
-	defer logReader.Close()
-	
-	// Use stdcopy to properly demux Docker streams
-	var stdout, stderr strings.Builder
-	if _, err := stdcopy.StdCopy(&stdout, &stderr, logReader); err != nil && err != io.EOF {
-		return nil, fmt.Errorf("failed to read file content: %w", err)
-	}
-	
-	// Check for errors in stderr
-	if stderr.Len() > 0 {
-		return nil, fmt.Errorf("failed to read file: %s", stderr.String())
-	}
-	
-	contentStr := stdout.String()
-	
-	return &models.VolumeFileContent{
-		Path:    filePath,
-		Content: contentStr,
-		Size:    int64(len(contentStr)),
-	}, nil
-}
-
 func (c *Client) RemoveVolume(ctx context.Context, volumeName string) error {
 	return c.cli.VolumeRemove(ctx, volumeName, false)
 }
@@ -0,0 +1,287 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+const (
+	// composeProjectLabel is the label Docker Compose sets to the project name
+	composeProjectLabel = "com.docker.compose.project"
+	// composeServiceLabel is the label Docker Compose sets to the service name
+	composeServiceLabel = "com.docker.compose.service"
+	// composeDependsOnLabel lists service dependencies as "service:condition[:restart]" entries
+	composeDependsOnLabel = "com.docker.compose.depends_on"
+)
+
+// Compose project actions
+const (
+	ComposeActionStart   = "start"
+	ComposeActionStop    = "stop"
+	ComposeActionRestart = "restart"
+)
+
+// ErrComposeProjectNotFound is returned when no container belongs to the requested project
+var ErrComposeProjectNotFound = errors.New("compose project not found")
+
+// ListComposeProjects groups containers by Docker Compose project and service
+func (m *Manager) ListComposeProjects(ctx context.Context) ([]models.ComposeProject, error) {
+	containers, err := m.ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byProject := make(map[string][]models.ContainerInfo)
+	for _, container := range containers {
+		if container.ComposeProject == "" {
+			continue
+		}
+		byProject[container.ComposeProject] = append(byProject[container.ComposeProject], container)
+	}
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	result := []models.ComposeProject{}
+	for name, projectContainers := range byProject {
+		result = append(result, buildComposeProject(name, projectContainers))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// GetComposeProject returns a single Docker Compose project
+func (m *Manager) GetComposeProject(ctx context.Context, projectName string) (*models.ComposeProject, error) {
+	containers, err := m.ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	projectContainers := []models.ContainerInfo{}
+	for _, container := range containers {
+		if container.ComposeProject == projectName {
+			projectContainers = append(projectContainers, container)
+		}
+	}
+	if len(projectContainers) == 0 {
+		return nil, ErrComposeProjectNotFound
+	}
+
+	project := buildComposeProject(projectName, projectContainers)
+	return &project, nil
+}
+
+// ComposeStopTimeout returns how long stopping all containers of a project may take in seconds,
+// or -1 if a container waits indefinitely. A nil timeout falls back to each container's stop
+// timeout label and then to defaultTimeout.
+func (m *Manager) ComposeStopTimeout(ctx context.Context, project *models.ComposeProject, timeout *int, defaultTimeout int) int {
+	client, release := m.currentClient()
+	defer release()
+
+	total := 0
+	for _, service := range project.Services {
+		for _, container := range service.Containers {
+			resolved := client.ResolveStopTimeout(ctx, container.ID, timeout, defaultTimeout)
+			if resolved < 0 {
+				return -1
+			}
+			total += resolved
+		}
+	}
+	return total
+}
+
+// ComposeProjectAction starts, stops or restarts every container of a Docker Compose project.
+// Containers are started in dependency order (dependencies first) and stopped in reverse order;
+// a restart stops all containers in reverse order and then starts them in dependency order.
+// Each container is processed even if a previous one failed; the outcome is reported per container.
+// For stop and restart, a nil timeout falls back to each container's stop timeout label and then
+// to defaultTimeout.
+func (m *Manager) ComposeProjectAction(ctx context.Context, project *models.ComposeProject, action string, timeout *int, defaultTimeout int) ([]models.ComposeOperationResult, error) {
+	switch action {
+	case ComposeActionStart, ComposeActionStop, ComposeActionRestart:
+	default:
+		return nil, fmt.Errorf("unsupported compose action: %s", action)
+	}
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	results := []models.ComposeOperationResult{}
+	errs := [][]error{}
+	for _, service := range orderComposeServices(project.Services) {
+		for _, container := range service.Containers {
+			results = append(results, models.ComposeOperationResult{
+				ContainerID: container.ID,
+				Name:        container.Name,
+				Service:     service.Name,
+			})
+			errs = append(errs, nil)
+		}
+	}
+
+	if action == ComposeActionStop || action == ComposeActionRestart {
+		for i := len(results) - 1; i >= 0; i-- {
+			if err := m.StopContainer(ctx, results[i].ContainerID, timeout, defaultTimeout); err != nil {
+				errs[i] = append(errs[i], err)
+			}
+		}
+	}
+	if action == ComposeActionStart || action == ComposeActionRestart {
+		for i := range results {
+			if err := m.StartContainer(ctx, results[i].ContainerID); err != nil {
+				errs[i] = append(errs[i], err)
+			}
+		}
+	}
+
+	for i := range results {
+		err := errors.Join(errs[i]...)
+		results[i].Success = err == nil
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+	if action == ComposeActionStop {
+		// Report containers in the order they were stopped
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
+	}
+
+	return results, nil
+}
+
+// buildComposeProject aggregates the containers of a project into services
+func buildComposeProject(name string, containers []models.ContainerInfo) models.ComposeProject {
+	project := models.ComposeProject{
+		Name:  name,
+		Total: len(containers),
+	}
+
+	serviceIndex := make(map[string]int)
+	for _, container := range containers {
+		if container.State == "running" {
+			project.Running++
+		}
+
+		serviceName := container.ComposeService
+		idx, ok := serviceIndex[serviceName]
+		if !ok {
+			idx = len(project.Services)
+			serviceIndex[serviceName] = idx
+			project.Services = append(project.Services, models.ComposeService{
+				Name:       serviceName,
+				DependsOn:  []string{},
+				Containers: []models.ContainerInfo{},
+			})
+		}
+
+		service := &project.Services[idx]
+		service.Containers = append(service.Containers, container)
+		for _, dep := range parseComposeDependsOn(container.Labels[composeDependsOnLabel]) {
+			if !containsString(service.DependsOn, dep) {
+				service.DependsOn = append(service.DependsOn, dep)
+			}
+		}
+	}
+
+	for i := range project.Services {
+		service := &project.Services[i]
+		running := 0
+		for _, container := range service.Containers {
+			if container.State == "running" {
+				running++
+			}
+		}
+		service.State = aggregateState(running, len(service.Containers))
+		sort.Strings(service.DependsOn)
+	}
+
+	sort.Slice(project.Services, func(i, j int) bool {
+		return project.Services[i].Name < project.Services[j].Name
+	})
+	project.State = aggregateState(project.Running, project.Total)
+
+	return project
+}
+
+// orderComposeServices sorts services so that every service comes after its dependencies.
+// Dependencies on services outside the project are ignored and dependency cycles are
+// broken at the first service visited twice.
+func orderComposeServices(services []models.ComposeService) []models.ComposeService {
+	byName := make(map[string]models.ComposeService, len(services))
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	ordered := make([]models.ComposeService, 0, len(services))
+	visited := make(map[string]bool, len(services))
+	visiting := make(map[string]bool)
+
+	var visit func(name string)
+	visit = func(name string) {
+		service, ok := byName[name]
+		if !ok || visited[name] || visiting[name] {
+			return
+		}
+		visiting[name] = true
+		for _, dep := range service.DependsOn {
+			visit(dep)
+		}
+		visiting[name] = false
+		visited[name] = true
+		ordered = append(ordered, service)
+	}
+
+	// Services are already sorted by name, which keeps the order deterministic
+	for _, service := range services {
+		visit(service.Name)
+	}
+
+	return ordered
+}
+
+// parseComposeDependsOn parses the depends_on label into service names.
+// Format: "db:service_healthy:false,cache:service_started:false" (older Compose versions omit the restart flag).
+func parseComposeDependsOn(label string) []string {
+	deps := []string{}
+	for _, entry := range strings.Split(label, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name := strings.SplitN(entry, ":", 2)[0]
+		if name != "" {
+			deps = append(deps, name)
+		}
+	}
+	return deps
+}
+
+// aggregateState summarizes how many of a group of containers are running
+func aggregateState(running, total int) string {
+	switch {
+	case total > 0 && running == total:
+		return "running"
+	case running > 0:
+		return "partial"
+	default:
+		return "stopped"
+	}
+}
+
+// containsString reports whether s is in list
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,389 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// isWithin reports whether p is root or a path below it
+func isWithin(p, root string) bool {
+	return root == "/" || p == root || strings.HasPrefix(p, root+"/")
+}
+
+// copyRegularFile opens a regular file in a container through the archive API. A symbolic link
+// resolving below root is followed once. The returned tar reader is positioned at the file
+// content; the returned closer releases the archive stream. Other file types fail with
+// ErrNotRegularFile.
+func (c *Client) copyRegularFile(ctx context.Context, containerID, p, root string) (*tar.Reader, *tar.Header, types.ContainerPathStat, io.Closer, error) {
+	for followed := false; ; followed = true {
+		content, stat, err := c.cli.CopyFromContainer(ctx, containerID, p)
+		if err != nil {
+			return nil, nil, stat, nil, err
+		}
+
+		tr := tar.NewReader(content)
+		header, err := tr.Next()
+		if err != nil {
+			content.Close()
+			return nil, nil, stat, nil, err
+		}
+		if header.Typeflag == tar.TypeReg {
+			return tr, header, stat, content, nil
+		}
+		content.Close()
+
+		link := stat.LinkTarget
+		if header.Typeflag != tar.TypeSymlink || followed || link == "" || !isWithin(link, root) {
+			return nil, nil, stat, nil, ErrNotRegularFile
+		}
+		p = link
+	}
+}
+
+// ListContainerFiles lists a directory in the filesystem of a running or stopped container,
+// including its volumes and bind mounts. The archive API returns the whole subtree, so listing
+// large directories takes as long as reading them; bound the time with ctx.
+func (c *Client) ListContainerFiles(ctx context.Context, containerID, dirPath string) ([]models.VolumeFileInfo, error) {
+	stat, err := c.cli.ContainerStatPath(ctx, containerID, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	// List the target of a symbolic link to a directory
+	source := dirPath
+	if stat.Mode&os.ModeSymlink != 0 && stat.LinkTarget != "" {
+		source = stat.LinkTarget
+		if stat, err = c.cli.ContainerStatPath(ctx, containerID, source); err != nil {
+			return nil, err
+		}
+	}
+	if !stat.Mode.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrNotDirectory, dirPath)
+	}
+
+	content, _, err := c.cli.CopyFromContainer(ctx, containerID, source)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	files := []models.VolumeFileInfo{}
+	tr := tar.NewReader(content)
+	// The first entry is the directory itself, its name prefixes all other entries
+	root, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container archive: %w", err)
+	}
+	prefix := strings.TrimSuffix(root.Name, "/") + "/"
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read container archive: %w", err)
+		}
+
+		name, ok := strings.CutPrefix(strings.TrimSuffix(header.Name, "/"), prefix)
+		if !ok || name == "" || strings.Contains(name, "/") {
+			// Not a direct child of the directory
+			continue
+		}
+
+		info := header.FileInfo()
+		size := int64(0)
+		if !info.IsDir() {
+			size = header.Size
+		}
+		files = append(files, models.VolumeFileInfo{
+			Name:        name,
+			Path:        path.Join(dirPath, name),
+			IsDirectory: info.IsDir(),
+			Size:        size,
+			Mode:        fileModeString(info.Mode()),
+			ModTime:     header.ModTime.UTC().Format(FileTimeFormat),
+		})
+	}
+
+	return files, nil
+}
+
+// OpenContainerFile opens a regular file in a running or stopped container for streaming.
+// A symbolic link to a file is followed.
+func (c *Client) OpenContainerFile(ctx context.Context, containerID, filePath string) (*FileStream, error) {
+	tr, header, stat, content, err := c.copyRegularFile(ctx, containerID, filePath, "/")
+	if err != nil {
+		if err == ErrNotRegularFile {
+			err = fmt.Errorf("%w: %s", ErrNotRegularFile, filePath)
+		}
+		return nil, err
+	}
+
+	return &FileStream{
+		Reader:  tr,
+		Name:    path.Base(filePath),
+		Size:    header.Size,
+		ModTime: stat.Mtime,
+		content: content,
+	}, nil
+}
+
+// ContainerUploadOptions holds the settings of a copy into a container
+type ContainerUploadOptions struct {
+	UID       *int   // Owner of all copied entries
+	GID       *int   // Group of all copied entries
+	Mode      *int64 // Permissions of copied files; directories keep theirs
+	Overwrite bool   // Replace existing files
+}
+
+// apply sets the configured owner and permissions on an archive entry
+func (o ContainerUploadOptions) apply(header *tar.Header) {
+	if o.UID != nil {
+		header.Uid, header.Uname = *o.UID, ""
+	}
+	if o.GID != nil {
+		header.Gid, header.Gname = *o.GID, ""
+	}
+	if o.Mode != nil && header.Typeflag == tar.TypeReg {
+		header.Mode = *o.Mode
+	}
+}
+
+// pathHeader returns the tar header of a path in a container, which unlike a stat carries its
+// owner. Only the first entry is read, so directories are not archived as a whole.
+func (c *Client) pathHeader(ctx context.Context, containerID, p string) (*tar.Header, error) {
+	content, _, err := c.cli.CopyFromContainer(ctx, containerID, p)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+	return tar.NewReader(content).Next()
+}
+
+// resolveContainerDir follows a symbolic link to a directory and checks that dir is one
+func (c *Client) resolveContainerDir(ctx context.Context, containerID, dir string) (string, error) {
+	stat, err := c.cli.ContainerStatPath(ctx, containerID, dir)
+	if err != nil {
+		return "", err
+	}
+	resolved := dir
+	if stat.Mode&os.ModeSymlink != 0 && stat.LinkTarget != "" {
+		resolved = stat.LinkTarget
+		if stat, err = c.cli.ContainerStatPath(ctx, containerID, resolved); err != nil {
+			return "", err
+		}
+	}
+	if !stat.Mode.IsDir() {
+		return "", fmt.Errorf("%w: %s", ErrNotDirectory, dir)
+	}
+	return resolved, nil
+}
+
+// checkUploadTarget checks whether a file may be written to p, and whether one exists there
+func (c *Client) checkUploadTarget(ctx context.Context, containerID, p string, overwrite bool) (bool, error) {
+	stat, err := c.cli.ContainerStatPath(ctx, containerID, p)
+	if client.IsErrNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if stat.Mode.IsDir() {
+		return false, fmt.Errorf("%s is a directory", p)
+	}
+	if !overwrite {
+		return false, fmt.Errorf("%w: %s", ErrFileExists, p)
+	}
+	return true, nil
+}
+
+// copyToContainer streams the entries written by write into a directory of a container
+func (c *Client) copyToContainer(ctx context.Context, containerID, dir string, write func(*tar.Writer) error) error {
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		if err := write(tw); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(tw.Close())
+	}()
+
+	err := c.cli.CopyToContainer(ctx, containerID, dir, pr, types.CopyToContainerOptions{})
+	pr.Close()
+	if err != nil {
+		return fmt.Errorf("failed to copy files: %w", err)
+	}
+	return nil
+}
+
+// UploadContainerFiles copies files into a directory of a running or stopped container.
+// Existing files are only replaced with opts.Overwrite and keep their owner and permissions;
+// new files take the owner of the directory. The owner and permissions in opts take precedence.
+func (c *Client) UploadContainerFiles(ctx context.Context, containerID, dir string, files []FileUpload, opts ContainerUploadOptions) (*models.ContainerUploadResult, error) {
+	resolved, err := c.resolveContainerDir(ctx, containerID, dir)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := c.pathHeader(ctx, containerID, resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	result := &models.ContainerUploadResult{
+		Directory:   dir,
+		Files:       []string{},
+		Overwritten: []string{},
+	}
+
+	// Check every file before writing any of them
+	headers := make([]*tar.Header, len(files))
+	for i, file := range files {
+		target := path.Join(resolved, file.Name)
+		exists, err := c.checkUploadTarget(ctx, containerID, target, opts.Overwrite)
+		if err != nil {
+			return nil, err
+		}
+		// Replaced files keep their owner, which only the archive header carries
+		var current *tar.Header
+		if exists {
+			if current, err = c.pathHeader(ctx, containerID, target); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path.Join(dir, file.Name), err)
+			}
+		}
+		headers[i] = newFileHeader(file.Name, file.Size, current, parent)
+		opts.apply(headers[i])
+
+		result.Files = append(result.Files, path.Join(dir, file.Name))
+		if exists {
+			result.Overwritten = append(result.Overwritten, path.Join(dir, file.Name))
+		}
+	}
+
+	err = c.copyToContainer(ctx, containerID, resolved, func(tw *tar.Writer) error {
+		for i, file := range files {
+			if err := tw.WriteHeader(headers[i]); err != nil {
+				return err
+			}
+			if _, err := io.Copy(tw, file.Content); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ExtractContainerArchive extracts a tar archive, optionally compressed, into a directory of a
+// running or stopped container. Entries keep their owner and permissions unless set in opts.
+// Existing files are only replaced with opts.Overwrite; directories are merged. The archive is
+// read twice, to check all entries before anything is written.
+func (c *Client) ExtractContainerArchive(ctx context.Context, containerID, dir string, archive io.ReadSeeker, opts ContainerUploadOptions) (*models.ContainerUploadResult, error) {
+	resolved, err := c.resolveContainerDir(ctx, containerID, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ContainerUploadResult{
+		Directory:   dir,
+		Files:       []string{},
+		Overwritten: []string{},
+	}
+
+	// Symbolic links created by the archive itself, which a stat before extraction cannot see
+	links := make(map[string]bool)
+	err = readArchive(archive, func(header *tar.Header, _ io.Reader) error {
+		name, err := archiveEntryPath(header)
+		if err != nil {
+			return err
+		}
+		// Without overwrite, a later entry could otherwise replace files through such a link
+		if link := linkAncestor(name, links); link != "" && !opts.Overwrite {
+			return fmt.Errorf("%w: entry %q is below the symbolic link %q of the same archive", ErrInvalidArchive, header.Name, link)
+		}
+		links[name] = header.Typeflag == tar.TypeSymlink
+		if header.Typeflag == tar.TypeDir {
+			return nil
+		}
+		exists, err := c.checkUploadTarget(ctx, containerID, path.Join(resolved, name), opts.Overwrite)
+		if err != nil {
+			return err
+		}
+
+		result.Files = append(result.Files, path.Join(dir, name))
+		if exists {
+			result.Overwritten = append(result.Overwritten, path.Join(dir, name))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	err = c.copyToContainer(ctx, containerID, resolved, func(tw *tar.Writer) error {
+		return readArchive(archive, func(header *tar.Header, content io.Reader) error {
+			opts.apply(header)
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			_, err := io.Copy(tw, content)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// archiveEntryPath returns the cleaned relative path of an archive entry, rejecting entries
+// that would be written outside of the target directory. Symbolic link targets are resolved
+// inside the container and not restricted.
+func archiveEntryPath(header *tar.Header) (string, error) {
+	if hasParentReference(header.Name) || (header.Typeflag == tar.TypeLink && hasParentReference(header.Linkname)) {
+		return "", fmt.Errorf("%w: entry %q is outside of the target directory", ErrInvalidArchive, header.Name)
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+	if name == "" {
+		name = "."
+	}
+	return name, nil
+}
+
+// linkAncestor returns the first parent directory of name that is a symbolic link in links
+func linkAncestor(name string, links map[string]bool) string {
+	for i := 0; i < len(name); i++ {
+		if name[i] == '/' && links[name[:i]] {
+			return name[:i]
+		}
+	}
+	return ""
+}
+
+// hasParentReference reports whether a path contains a ".." element
+func hasParentReference(p string) bool {
+	for _, part := range strings.Split(p, "/") {
+		if part == ".." {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"archive/tar"
+	"errors"
+	"testing"
+)
+
+func TestArchiveEntryPath(t *testing.T) {
+	tests := []struct {
+		header  tar.Header
+		want    string
+		invalid bool
+	}{
+		{header: tar.Header{Name: "conf.d/default.conf"}, want: "conf.d/default.conf"},
+		{header: tar.Header{Name: "./conf.d/"}, want: "conf.d"},
+		{header: tar.Header{Name: "/etc/hosts"}, want: "etc/hosts"},
+		{header: tar.Header{Name: "./"}, want: "."},
+		{header: tar.Header{Name: "../hosts"}, invalid: true},
+		{header: tar.Header{Name: "conf.d/../../hosts"}, invalid: true},
+		{header: tar.Header{Name: "link", Typeflag: tar.TypeLink, Linkname: "../hosts"}, invalid: true},
+		{header: tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../hosts"}, want: "link"},
+	}
+
+	for _, tt := range tests {
+		got, err := archiveEntryPath(&tt.header)
+		if tt.invalid {
+			if !errors.Is(err, ErrInvalidArchive) {
+				t.Errorf("archiveEntryPath(%q) error = %v, want ErrInvalidArchive", tt.header.Name, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("archiveEntryPath(%q) = %q, %v, want %q", tt.header.Name, got, err, tt.want)
+		}
+	}
+}
+
+func TestLinkAncestor(t *testing.T) {
+	links := map[string]bool{"etc": true, "conf.d": false, "conf.d/sites": true}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"etc", ""},
+		{"etc/passwd", "etc"},
+		{"etc/ssl/cert.pem", "etc"},
+		{"etcetera/file", ""},
+		{"conf.d/default.conf", ""},
+		{"conf.d/sites/default", "conf.d/sites"},
+	}
+
+	for _, tt := range tests {
+		if got := linkAncestor(tt.name, links); got != tt.want {
+			t.Errorf("linkAncestor(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// CreateExec creates an exec instance running cmd inside a container
+func (c *Client) CreateExec(ctx context.Context, containerID string, cmd []string, tty bool) (string, error) {
+	execConfig := types.ExecConfig{
+		Tty:          tty,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	}
+
+	resp, err := c.cli.ContainerExecCreate(ctx, containerID, execConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to create exec instance: %w", err)
+	}
+
+	return resp.ID, nil
+}
+
+// AttachExec starts an exec instance and attaches to its standard streams.
+// When tty is false the output stream is multiplexed and must be demuxed with stdcopy.
+func (c *Client) AttachExec(ctx context.Context, execID string, tty bool) (types.HijackedResponse, error) {
+	resp, err := c.cli.ContainerExecAttach(ctx, execID, types.ExecStartCheck{Tty: tty})
+	if err != nil {
+		return types.HijackedResponse{}, fmt.Errorf("failed to attach to exec instance: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ResizeExec resizes the TTY of an exec instance
+func (c *Client) ResizeExec(ctx context.Context, execID string, height, width uint) error {
+	return c.cli.ContainerExecResize(ctx, execID, container.ResizeOptions{
+		Height: height,
+		Width:  width,
+	})
+}
+
+// InspectExec returns the running state and exit code of an exec instance
+func (c *Client) InspectExec(ctx context.Context, execID string) (types.ContainerExecInspect, error) {
+	return c.cli.ContainerExecInspect(ctx, execID)
+}
@@ -0,0 +1,266 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// shortImageID strips the digest algorithm prefix and shortens an image ID
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > shortIDLength {
+		id = id[:shortIDLength]
+	}
+	return id
+}
+
+// imageContainers builds a map of full image ID to the IDs of containers using it
+func (c *Client) imageContainers(ctx context.Context) (map[string][]string, error) {
+	containers, err := c.cli.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string)
+	for _, container := range containers {
+		result[container.ImageID] = append(result[container.ImageID], container.ID[:shortIDLength])
+	}
+	return result, nil
+}
+
+// ListImages lists all images with the containers using them, newest first
+func (c *Client) ListImages(ctx context.Context) ([]models.ImageInfo, error) {
+	images, err := c.cli.ImageList(ctx, types.ImageListOptions{All: false})
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := c.imageContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	result := []models.ImageInfo{}
+	for _, img := range images {
+		result = append(result, buildImageInfo(img.ID, img.RepoTags, img.RepoDigests, img.Size, img.Created, usage[img.ID]))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Created > result[j].Created
+	})
+
+	return result, nil
+}
+
+// InspectImage gets detailed information about an image, including its layers and history
+func (c *Client) InspectImage(ctx context.Context, imageID string) (*models.ImageDetail, error) {
+	inspect, _, err := c.cli.ImageInspectWithRaw(ctx, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect image: %w", err)
+	}
+
+	history, err := c.cli.ImageHistory(ctx, inspect.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image history: %w", err)
+	}
+
+	usage, err := c.imageContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	createdTime, _ := time.Parse(time.RFC3339Nano, inspect.Created)
+
+	detail := &models.ImageDetail{
+		ImageInfo:    buildImageInfo(inspect.ID, inspect.RepoTags, inspect.RepoDigests, inspect.Size, createdTime.Unix(), usage[inspect.ID]),
+		Architecture: inspect.Architecture,
+		Os:           inspect.Os,
+		Author:       inspect.Author,
+		Layers:       inspect.RootFS.Layers,
+		History:      []models.ImageHistoryEntry{},
+	}
+	if detail.Layers == nil {
+		detail.Layers = []string{}
+	}
+
+	if inspect.Config != nil {
+		detail.Cmd = inspect.Config.Cmd
+		detail.Entrypoint = inspect.Config.Entrypoint
+		detail.Env = inspect.Config.Env
+		detail.Labels = inspect.Config.Labels
+		for port := range inspect.Config.ExposedPorts {
+			detail.ExposedPorts = append(detail.ExposedPorts, string(port))
+		}
+		sort.Strings(detail.ExposedPorts)
+	}
+
+	for _, entry := range history {
+		id := entry.ID
+		if id != "<missing>" {
+			id = shortImageID(id)
+		}
+		detail.History = append(detail.History, models.ImageHistoryEntry{
+			ID:        id,
+			Created:   entry.Created,
+			CreatedBy: entry.CreatedBy,
+			Size:      entry.Size,
+			Tags:      entry.Tags,
+			Comment:   entry.Comment,
+		})
+	}
+
+	return detail, nil
+}
+
+// RemoveImage removes an image. With force, the image is removed even if it is used by
+// stopped containers or has multiple tags; with noPrune, untagged parent images are kept.
+func (c *Client) RemoveImage(ctx context.Context, imageID string, force, noPrune bool) ([]models.ImageDeleteResult, error) {
+	responses, err := c.cli.ImageRemove(ctx, imageID, types.ImageRemoveOptions{
+		Force:         force,
+		PruneChildren: !noPrune,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return convertDeleteResponses(responses), nil
+}
+
+// TagImage adds a repository:tag reference to an image
+func (c *Client) TagImage(ctx context.Context, imageID, target string) error {
+	return c.cli.ImageTag(ctx, imageID, target)
+}
+
+// PruneImages removes unused images. By default only dangling (untagged) images are
+// removed; with all set, every image not used by a container is removed.
+func (c *Client) PruneImages(ctx context.Context, all bool) (*models.ImagePruneResult, error) {
+	pruneFilters := filters.NewArgs()
+	if all {
+		pruneFilters.Add("dangling", "false")
+	}
+
+	report, err := c.cli.ImagesPrune(ctx, pruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ImagePruneResult{
+		ImagesDeleted:  convertDeleteResponses(report.ImagesDeleted),
+		SpaceReclaimed: report.SpaceReclaimed,
+	}, nil
+}
+
+// buildImageInfo assembles the image model shared by list and inspect
+func buildImageInfo(id string, repoTags, repoDigests []string, size, created int64, containers []string) models.ImageInfo {
+	// Untagged images are reported with a "<none>:<none>" placeholder by older daemons
+	tags := []string{}
+	for _, tag := range repoTags {
+		if tag != "<none>:<none>" {
+			tags = append(tags, tag)
+		}
+	}
+	digests := []string{}
+	for _, digest := range repoDigests {
+		if digest != "<none>@<none>" {
+			digests = append(digests, digest)
+		}
+	}
+	if containers == nil {
+		containers = []string{}
+	}
+
+	return models.ImageInfo{
+		ID:          shortImageID(id),
+		RepoTags:    tags,
+		RepoDigests: digests,
+		Size:        size,
+		Created:     created,
+		Dangling:    len(tags) == 0,
+		Containers:  containers,
+	}
+}
+
+// convertDeleteResponses converts Docker image delete responses into API models
+func convertDeleteResponses(responses []image.DeleteResponse) []models.ImageDeleteResult {
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	result := []models.ImageDeleteResult{}
+	for _, resp := range responses {
+		result = append(result, models.ImageDeleteResult{
+			Untagged: resp.Untagged,
+			Deleted:  resp.Deleted,
+		})
+	}
+	return result
+}
+
+// SaveImage exports an image with all its layers and tags as a tar archive (docker save)
+func (c *Client) SaveImage(ctx context.Context, imageID string) (io.ReadCloser, error) {
+	return c.cli.ImageSave(ctx, []string{imageID})
+}
+
+// LoadImage imports images from a tar archive created by docker save (optionally compressed)
+// and reports the load progress line by line through onLine. It returns the loaded image
+// references, or image IDs for untagged images.
+func (c *Client) LoadImage(ctx context.Context, input io.Reader, onLine func(string) error) ([]string, error) {
+	resp, err := c.cli.ImageLoad(ctx, input, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	loaded := []string{}
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to read load output: %w", err)
+		}
+		if msg.Error != nil {
+			return nil, msg.Error
+		}
+
+		text := strings.TrimSpace(msg.Stream)
+		if text == "" && msg.Status != "" {
+			text = msg.Status
+			if msg.ID != "" {
+				text = msg.ID + ": " + text
+			}
+			if msg.Progress != nil && msg.Progress.Total > 0 {
+				text += fmt.Sprintf(" %d/%d", msg.Progress.Current, msg.Progress.Total)
+			}
+		}
+		if text == "" {
+			continue
+		}
+
+		// e.g. "Loaded image: nginx:latest" or "Loaded image ID: sha256:..."
+		if ref, ok := strings.CutPrefix(text, "Loaded image: "); ok {
+			loaded = append(loaded, ref)
+		} else if id, ok := strings.CutPrefix(text, "Loaded image ID: "); ok {
+			loaded = append(loaded, id)
+		}
+
+		if err := onLine(text); err != nil {
+			return nil, err
+		}
+	}
+
+	return loaded, nil
+}
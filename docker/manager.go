@@ -7,12 +7,25 @@ import (
 	"log"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
 
 	"github.com/dev-zapi/docker-simple-panel/models"
+	"github.com/dev-zapi/docker-simple-panel/registry"
+)
+
+const (
+	// maxConcurrentStats bounds the number of parallel stats requests made to the daemon
+	maxConcurrentStats = 8
+	// statsSampleTime is how long a single stats request may take; the daemon waits for a
+	// second reading to compute CPU usage
+	statsSampleTime = 3 * time.Second
 )
 
-// ErrSelfOperation is returned when attempting to stop/restart the container running this application
-var ErrSelfOperation = errors.New("cannot stop or restart the container running this application")
+// ErrSelfOperation is returned when attempting a destructive operation (stop, restart, pause,
+// kill, remove, recreate, network disconnect) on the container running this application
+var ErrSelfOperation = errors.New("cannot stop, restart, pause, kill, remove, recreate or disconnect the container running this application")
 
 // Manager manages Docker client with support for runtime socket path changes
 type Manager struct {
@@ -20,6 +33,8 @@ type Manager struct {
 	client               *Client
 	socketPath           string
 	containerEnvironment ContainerEnvironment
+	updates              *UpdateChecker
+	explorer             *volumeExplorer
 }
 
 // NewManager creates a new Docker client manager
@@ -37,10 +52,19 @@ func NewManager(socketPath string) (*Manager, error) {
 		log.Println("Running outside container environment")
 	}
 
+	// Helper containers left behind by a previous process are no longer tracked
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := client.removeVolumeHelpers(ctx); err != nil {
+		log.Printf("Warning: failed to remove leftover volume helper containers: %v", err)
+	}
+
 	return &Manager{
 		client:               client,
 		socketPath:           socketPath,
 		containerEnvironment: env,
+		updates:              NewUpdateChecker(registry.NewClient(nil)),
+		explorer:             newVolumeExplorer(),
 	}, nil
 }
 
@@ -75,18 +99,26 @@ func (m *Manager) isSelfContainer(containerID string) bool {
 	return strings.EqualFold(selfID, targetID)
 }
 
-// RestartWithSocket restarts the Docker client with a new socket path
-func (m *Manager) RestartWithSocket(newSocketPath string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// checkNotSelf returns ErrSelfOperation if containerID refers to this application's container.
+// The target is inspected first, so names and ID prefixes of any length are caught as well.
+func (m *Manager) checkNotSelf(ctx context.Context, client *Client, containerID string) error {
+	if !m.containerEnvironment.IsInContainer || m.containerEnvironment.ContainerID == "" {
+		return nil
+	}
 
-	// Close existing client
-	if m.client != nil {
-		if err := m.client.Close(); err != nil {
-			log.Printf("Warning: failed to close existing Docker client: %v", err)
-		}
+	fullID, err := client.ResolveContainerID(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	if m.isSelfContainer(fullID) {
+		return ErrSelfOperation
 	}
+	return nil
+}
 
+// RestartWithSocket restarts the Docker client with a new socket path. The previous client is
+// closed once the operations still using it have finished.
+func (m *Manager) RestartWithSocket(newSocketPath string) error {
 	// Create new client with new socket path
 	newClient, err := NewClient(newSocketPath)
 	if err != nil {
@@ -103,13 +135,55 @@ func (m *Manager) RestartWithSocket(newSocketPath string) error {
 		return err
 	}
 
+	m.mu.Lock()
+	oldClient := m.client
 	m.client = newClient
 	m.socketPath = newSocketPath
+	m.mu.Unlock()
 	log.Printf("Docker client restarted with socket: %s", newSocketPath)
 
+	// Explorer helpers belong to the previous daemon
+	m.explorer.ReleaseAll()
+
+	// Close existing client
+	if oldClient != nil {
+		go func() {
+			oldClient.inFlight.Wait()
+			if err := oldClient.Close(); err != nil {
+				log.Printf("Warning: failed to close existing Docker client: %v", err)
+			}
+		}()
+	}
+
 	return nil
 }
 
+// currentClient returns the current client for an operation that runs without holding the
+// lock. Streams, pulls and other long or multi-step operations use it, as holding the lock for
+// their whole duration would block socket changes. The client is not closed before release
+// is called, even if the socket changes in the meantime.
+func (m *Manager) currentClient() (*Client, func()) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	client := m.client
+	client.inFlight.Add(1)
+	var once sync.Once
+	return client, func() { once.Do(client.inFlight.Done) }
+}
+
+// releasingReadCloser is a stream read from a client obtained with currentClient. Closing the
+// stream releases the client.
+type releasingReadCloser struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releasingReadCloser) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
+}
+
 // GetSocketPath returns the current socket path
 func (m *Manager) GetSocketPath() string {
 	m.mu.RLock()
@@ -130,6 +204,7 @@ func (m *Manager) ListContainers(ctx context.Context) ([]models.ContainerInfo, e
 	// Mark self-container
 	for i := range containers {
 		containers[i].IsSelf = m.isSelfContainer(containers[i].ID)
+		containers[i].UpdateAvailable = m.updates.updateAvailable(containers[i].Image, containers[i].ImageID)
 	}
 
 	return containers, nil
@@ -147,6 +222,7 @@ func (m *Manager) GetContainerInfo(ctx context.Context, containerID string) (*mo
 
 	// Mark self-container
 	info.IsSelf = m.isSelfContainer(info.ID)
+	info.UpdateAvailable = m.updates.updateAvailable(info.Image, info.ImageID)
 
 	return info, nil
 }
@@ -158,28 +234,141 @@ func (m *Manager) StartContainer(ctx context.Context, containerID string) error
 	return m.client.StartContainer(ctx, containerID)
 }
 
-// StopContainer stops a container
-func (m *Manager) StopContainer(ctx context.Context, containerID string) error {
+// ResolveStopTimeout returns the stop timeout of a container in seconds. A nil timeout falls
+// back to the container's stop timeout label and then to defaultTimeout.
+func (m *Manager) ResolveStopTimeout(ctx context.Context, containerID string, timeout *int, defaultTimeout int) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client.ResolveStopTimeout(ctx, containerID, timeout, defaultTimeout)
+}
+
+// StopContainer stops a container. A nil timeout falls back to the container's
+// stop timeout label and then to defaultTimeout.
+func (m *Manager) StopContainer(ctx context.Context, containerID string, timeout *int, defaultTimeout int) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	// Check if attempting to stop self
-	if m.isSelfContainer(containerID) {
-		return ErrSelfOperation
+	if err := m.checkNotSelf(ctx, m.client, containerID); err != nil {
+		return err
 	}
+	resolved := m.client.ResolveStopTimeout(ctx, containerID, timeout, defaultTimeout)
+	return m.client.StopContainer(ctx, containerID, resolved)
+}
 
+// RestartContainer restarts a container. A nil timeout falls back to the container's
+// stop timeout label and then to defaultTimeout.
+func (m *Manager) RestartContainer(ctx context.Context, containerID string, timeout *int, defaultTimeout int) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.client.StopContainer(ctx, containerID)
-}
 
-// RestartContainer restarts a container
-func (m *Manager) RestartContainer(ctx context.Context, containerID string) error {
 	// Check if attempting to restart self
-	if m.isSelfContainer(containerID) {
-		return ErrSelfOperation
+	if err := m.checkNotSelf(ctx, m.client, containerID); err != nil {
+		return err
+	}
+	resolved := m.client.ResolveStopTimeout(ctx, containerID, timeout, defaultTimeout)
+	return m.client.RestartContainer(ctx, containerID, resolved)
+}
+
+// PauseContainer pauses a container
+func (m *Manager) PauseContainer(ctx context.Context, containerID string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Check if attempting to pause self
+	if err := m.checkNotSelf(ctx, m.client, containerID); err != nil {
+		return err
+	}
+	return m.client.PauseContainer(ctx, containerID)
+}
+
+// UnpauseContainer unpauses a container
+func (m *Manager) UnpauseContainer(ctx context.Context, containerID string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client.UnpauseContainer(ctx, containerID)
+}
+
+// KillContainer sends a signal to a container
+func (m *Manager) KillContainer(ctx context.Context, containerID, signal string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Check if attempting to signal self
+	if err := m.checkNotSelf(ctx, m.client, containerID); err != nil {
+		return err
 	}
+	return m.client.KillContainer(ctx, containerID, signal)
+}
 
+// RemoveContainer removes a container
+func (m *Manager) RemoveContainer(ctx context.Context, containerID string, force, removeVolumes bool) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.client.RestartContainer(ctx, containerID)
+
+	// Check if attempting to remove self
+	if err := m.checkNotSelf(ctx, m.client, containerID); err != nil {
+		return err
+	}
+	return m.client.RemoveContainer(ctx, containerID, force, removeVolumes)
+}
+
+// RecreateContainer replaces a container with a new one created from the same settings.
+// A nil stop timeout falls back to the container's stop timeout label and then to defaultTimeout.
+func (m *Manager) RecreateContainer(ctx context.Context, containerID string, pull bool, timeout *int, defaultTimeout int, healthTimeout time.Duration) (*models.RecreateResult, error) {
+	client, release := m.currentClient()
+	defer release()
+
+	// Check if attempting to recreate self
+	if err := m.checkNotSelf(ctx, client, containerID); err != nil {
+		return nil, err
+	}
+
+	return client.RecreateContainer(ctx, containerID, RecreateOptions{
+		Pull:          pull,
+		StopTimeout:   client.ResolveStopTimeout(ctx, containerID, timeout, defaultTimeout),
+		HealthTimeout: healthTimeout,
+	})
+}
+
+// ListContainerFiles lists a directory in the filesystem of a container
+func (m *Manager) ListContainerFiles(ctx context.Context, containerID, dirPath string) ([]models.VolumeFileInfo, error) {
+	client, release := m.currentClient()
+	defer release()
+	return client.ListContainerFiles(ctx, containerID, dirPath)
+}
+
+// OpenContainerFile opens a regular file in a container for streaming
+func (m *Manager) OpenContainerFile(ctx context.Context, containerID, filePath string) (*FileStream, error) {
+	client, release := m.currentClient()
+	file, err := client.OpenContainerFile(ctx, containerID, filePath)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	file.release = release
+	return file, nil
+}
+
+// ContainerChanges lists the paths changed in a container's writable layer
+func (m *Manager) ContainerChanges(ctx context.Context, containerID string, sizes bool) (*models.ContainerChanges, error) {
+	client, release := m.currentClient()
+	defer release()
+	return client.ContainerChanges(ctx, containerID, sizes)
+}
+
+// UploadContainerFiles copies files into a directory of a container
+func (m *Manager) UploadContainerFiles(ctx context.Context, containerID, dir string, files []FileUpload, opts ContainerUploadOptions) (*models.ContainerUploadResult, error) {
+	client, release := m.currentClient()
+	defer release()
+	return client.UploadContainerFiles(ctx, containerID, dir, files, opts)
+}
+
+// ExtractContainerArchive extracts a tar archive into a directory of a container
+func (m *Manager) ExtractContainerArchive(ctx context.Context, containerID, dir string, archive io.ReadSeeker, opts ContainerUploadOptions) (*models.ContainerUploadResult, error) {
+	client, release := m.currentClient()
+	defer release()
+	return client.ExtractContainerArchive(ctx, containerID, dir, archive, opts)
 }
 
 // ListVolumes lists all Docker volumes with container associations
@@ -189,13 +378,223 @@ func (m *Manager) ListVolumes(ctx context.Context) ([]models.VolumeInfo, error)
 	return m.client.ListVolumes(ctx)
 }
 
+// CreateVolume creates a Docker volume
+func (m *Manager) CreateVolume(ctx context.Context, opts CreateVolumeOptions) (*models.VolumeInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client.CreateVolume(ctx, opts)
+}
+
+// PruneVolumes removes unused volumes, or only reports them in a dry run
+func (m *Manager) PruneVolumes(ctx context.Context, all, dryRun bool) (*models.VolumePruneResult, error) {
+	if dryRun {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+	} else {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		// Explorer helpers would keep the volumes they explore from being pruned
+		defer m.explorer.Suspend("")()
+	}
+	return m.client.PruneVolumes(ctx, all, dryRun)
+}
+
 // RemoveVolume removes a Docker volume by name
 func (m *Manager) RemoveVolume(ctx context.Context, volumeName string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	// The explorer helper would keep the volume in use
+	defer m.explorer.Suspend(volumeName)()
 	return m.client.RemoveVolume(ctx, volumeName)
 }
 
+// BackupVolume streams the contents of a volume as a gzip-compressed tar archive
+func (m *Manager) BackupVolume(ctx context.Context, volumeName, explorerImage string) (io.ReadCloser, error) {
+	client, release := m.currentClient()
+	archive, err := client.BackupVolume(ctx, volumeName, explorerImage)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &releasingReadCloser{ReadCloser: archive, release: release}, nil
+}
+
+// RestoreVolume extracts an archive into a volume, optionally stopping the containers using it
+func (m *Manager) RestoreVolume(ctx context.Context, volumeName, explorerImage string, archive io.ReadSeeker, opts RestoreVolumeOptions) (*models.VolumeRestoreResult, error) {
+	client, release := m.currentClient()
+	defer release()
+
+	// Check if the restore would stop this application
+	if opts.StopContainers {
+		running, err := client.runningVolumeContainers(ctx, volumeName)
+		if err != nil {
+			return nil, err
+		}
+		for _, containerID := range running {
+			if m.isSelfContainer(containerID) {
+				return nil, ErrSelfOperation
+			}
+		}
+	}
+
+	return client.RestoreVolume(ctx, volumeName, explorerImage, archive, opts)
+}
+
+// ListImages lists all images with the containers using them
+func (m *Manager) ListImages(ctx context.Context) ([]models.ImageInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client.ListImages(ctx)
+}
+
+// InspectImage gets detailed information about an image
+func (m *Manager) InspectImage(ctx context.Context, imageID string) (*models.ImageDetail, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client.InspectImage(ctx, imageID)
+}
+
+// RemoveImage removes an image
+func (m *Manager) RemoveImage(ctx context.Context, imageID string, force, noPrune bool) ([]models.ImageDeleteResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client.RemoveImage(ctx, imageID, force, noPrune)
+}
+
+// TagImage adds a repository:tag reference to an image
+func (m *Manager) TagImage(ctx context.Context, imageID, target string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client.TagImage(ctx, imageID, target)
+}
+
+// PruneImages removes unused images
+func (m *Manager) PruneImages(ctx context.Context, all bool) (*models.ImagePruneResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client.PruneImages(ctx, all)
+}
+
+// PullImage pulls an image, reporting aggregated progress through onProgress
+func (m *Manager) PullImage(ctx context.Context, ref string, onProgress func(*models.ImagePullProgress) error) (*models.ImagePullProgress, error) {
+	client, release := m.currentClient()
+	defer release()
+	return client.PullImage(ctx, ref, onProgress)
+}
+
+// CheckImageUpdates compares the images of running containers with their registries.
+// Registry lookups are cached unless refresh is set.
+func (m *Manager) CheckImageUpdates(ctx context.Context, refresh bool) ([]models.ImageUpdateStatus, error) {
+	client, release := m.currentClient()
+	defer release()
+	return m.updates.Check(ctx, client, refresh)
+}
+
+// SetRegistryHosts sets the registry credentials used for update checks
+func (m *Manager) SetRegistryHosts(hosts map[string]registry.Host) {
+	m.updates.SetRegistryHosts(hosts)
+}
+
+// BuildImage builds an image from a tar build context, reporting output through onLine
+func (m *Manager) BuildImage(ctx context.Context, buildContext io.Reader, opts BuildOptions, onLine func(string) error) (string, error) {
+	client, release := m.currentClient()
+	defer release()
+	return client.BuildImage(ctx, buildContext, opts, onLine)
+}
+
+// SaveImage exports an image as a tar archive; the caller must close the reader
+func (m *Manager) SaveImage(ctx context.Context, imageID string) (io.ReadCloser, error) {
+	client, release := m.currentClient()
+	archive, err := client.SaveImage(ctx, imageID)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &releasingReadCloser{ReadCloser: archive, release: release}, nil
+}
+
+// LoadImage imports images from a tar archive, reporting progress through onLine
+func (m *Manager) LoadImage(ctx context.Context, input io.Reader, onLine func(string) error) ([]string, error) {
+	client, release := m.currentClient()
+	defer release()
+	return client.LoadImage(ctx, input, onLine)
+}
+
+// Topology builds the container and network graph
+func (m *Manager) Topology(ctx context.Context) (*models.Topology, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	topology, err := m.client.Topology(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark self-container
+	for i := range topology.Nodes {
+		node := &topology.Nodes[i]
+		if node.Type == TopologyContainerNode {
+			node.IsSelf = m.isSelfContainer(strings.TrimPrefix(node.ID, TopologyContainerNode+":"))
+		}
+	}
+
+	return topology, nil
+}
+
+// ListNetworks lists all networks with their attached containers
+func (m *Manager) ListNetworks(ctx context.Context) ([]models.Network, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client.ListNetworks(ctx)
+}
+
+// InspectNetwork gets a single network by ID or name
+func (m *Manager) InspectNetwork(ctx context.Context, networkID string) (*models.Network, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client.InspectNetwork(ctx, networkID)
+}
+
+// CreateNetwork creates a network and returns its ID
+func (m *Manager) CreateNetwork(ctx context.Context, opts CreateNetworkOptions) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client.CreateNetwork(ctx, opts)
+}
+
+// RemoveNetwork removes a network
+func (m *Manager) RemoveNetwork(ctx context.Context, networkID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client.RemoveNetwork(ctx, networkID)
+}
+
+// PruneNetworks removes all unused networks
+func (m *Manager) PruneNetworks(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client.PruneNetworks(ctx)
+}
+
+// ConnectNetwork attaches a container to a network
+func (m *Manager) ConnectNetwork(ctx context.Context, networkID, containerID string, opts ConnectNetworkOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client.ConnectNetwork(ctx, networkID, containerID, opts)
+}
+
+// DisconnectNetwork detaches a container from a network
+func (m *Manager) DisconnectNetwork(ctx context.Context, networkID, containerID string, force bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Check if attempting to disconnect self, which could cut off access to the panel
+	if err := m.checkNotSelf(ctx, m.client, containerID); err != nil {
+		return err
+	}
+	return m.client.DisconnectNetwork(ctx, networkID, containerID, force)
+}
+
 // Ping checks if the Docker daemon is accessible
 func (m *Manager) Ping(ctx context.Context) error {
 	m.mu.RLock()
@@ -210,24 +609,210 @@ func (m *Manager) ContainerLogs(ctx context.Context, containerID string, follow
 	return m.client.ContainerLogs(ctx, containerID, follow)
 }
 
+// ContainerStats gets a single resource usage sample for a container
+func (m *Manager) ContainerStats(ctx context.Context, containerID string) (*models.ContainerStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client.ContainerStats(ctx, containerID)
+}
+
+// StreamContainerStats streams resource usage samples for a container
+func (m *Manager) StreamContainerStats(ctx context.Context, containerID string, onSample func(*models.ContainerStats) error) error {
+	client, release := m.currentClient()
+	defer release()
+	return client.StreamContainerStats(ctx, containerID, onSample)
+}
+
+// ListContainerStats collects a resource usage sample for every running container.
+// Samples are gathered in parallel with bounded concurrency; containers that stop or fail
+// mid-collection are reported with a stats error instead of failing the whole call.
+// onStart, if set, is called before sampling with an estimate of how long it will take.
+func (m *Manager) ListContainerStats(ctx context.Context, onStart func(estimate time.Duration)) ([]models.ContainerStatsSnapshot, error) {
+	client, release := m.currentClient()
+	defer release()
+
+	containers, err := client.ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	result := []models.ContainerStatsSnapshot{}
+	for _, container := range containers {
+		if container.State != "running" {
+			continue
+		}
+		container.IsSelf = m.isSelfContainer(container.ID)
+		result = append(result, models.ContainerStatsSnapshot{ContainerInfo: container})
+	}
+
+	if onStart != nil {
+		rounds := (len(result) + maxConcurrentStats - 1) / maxConcurrentStats
+		onStart(time.Duration(rounds) * statsSampleTime)
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentStats)
+	for i := range result {
+		wg.Add(1)
+		go func(snapshot *models.ContainerStatsSnapshot) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				snapshot.StatsError = ctx.Err().Error()
+				return
+			}
+
+			stats, err := client.ContainerStats(ctx, snapshot.ID)
+			if err != nil {
+				snapshot.StatsError = err.Error()
+				return
+			}
+			snapshot.Stats = stats
+		}(&result[i])
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// CreateExec creates an exec instance inside a container
+func (m *Manager) CreateExec(ctx context.Context, containerID string, cmd []string, tty bool) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client.CreateExec(ctx, containerID, cmd, tty)
+}
+
+// AttachExec starts an exec instance and attaches to its standard streams
+func (m *Manager) AttachExec(ctx context.Context, execID string, tty bool) (types.HijackedResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client.AttachExec(ctx, execID, tty)
+}
+
+// ResizeExec resizes the TTY of an exec instance
+func (m *Manager) ResizeExec(ctx context.Context, execID string, height, width uint) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client.ResizeExec(ctx, execID, height, width)
+}
+
+// InspectExec returns the state of an exec instance
+func (m *Manager) InspectExec(ctx context.Context, execID string) (types.ContainerExecInspect, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client.InspectExec(ctx, execID)
+}
+
 // ExploreVolumeFiles lists files and directories in a volume path
 func (m *Manager) ExploreVolumeFiles(ctx context.Context, volumeName, path, explorerImage string) ([]models.VolumeFileInfo, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.client.ExploreVolumeFiles(ctx, volumeName, path, explorerImage)
+
+	helperID, release, err := m.explorer.acquire(ctx, m.client, volumeName, explorerImage)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return m.client.listVolumeDirectory(ctx, helperID, path)
 }
 
 // ReadVolumeFile reads the content of a file in a volume
 func (m *Manager) ReadVolumeFile(ctx context.Context, volumeName, filePath, explorerImage string) (*models.VolumeFileContent, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.client.ReadVolumeFile(ctx, volumeName, filePath, explorerImage)
+
+	helperID, release, err := m.explorer.acquire(ctx, m.client, volumeName, explorerImage)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return m.client.readVolumeFile(ctx, helperID, filePath)
+}
+
+// OpenVolumeFile opens a regular file in a volume for streaming
+func (m *Manager) OpenVolumeFile(ctx context.Context, volumeName, filePath, explorerImage string) (*FileStream, error) {
+	client, release := m.currentClient()
+	helperID, releaseHelper, err := m.explorer.acquire(ctx, client, volumeName, explorerImage)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return client.openVolumeFile(ctx, helperID, filePath, func() {
+		releaseHelper()
+		release()
+	})
+}
+
+// ArchiveVolumeDirectory streams a directory of a volume as a tar or zip archive
+func (m *Manager) ArchiveVolumeDirectory(ctx context.Context, volumeName, dirPath, format, explorerImage string) (io.ReadCloser, error) {
+	client, release := m.currentClient()
+	helperID, releaseHelper, err := m.explorer.acquire(ctx, client, volumeName, explorerImage)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return client.archiveVolumeDirectory(ctx, helperID, volumeName, dirPath, format, func() {
+		releaseHelper()
+		release()
+	})
+}
+
+// SearchVolume searches file names and contents in a volume, reporting matches as they are found
+func (m *Manager) SearchVolume(ctx context.Context, volumeName string, opts VolumeSearchOptions, explorerImage string, onMatch func(models.VolumeSearchMatch) error) (*models.VolumeSearchResult, error) {
+	client, release := m.currentClient()
+	defer release()
+	helperID, releaseHelper, err := m.explorer.acquire(ctx, client, volumeName, explorerImage)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseHelper()
+	return client.searchVolume(ctx, helperID, opts, onMatch)
+}
+
+// WriteVolumeFile replaces or creates a file in a volume
+func (m *Manager) WriteVolumeFile(ctx context.Context, volumeName, filePath string, content []byte, expected *FileVersion, overwrite bool, explorerImage string) (*models.VolumeFileInfo, error) {
+	client, release := m.currentClient()
+	defer release()
+	return client.WriteVolumeFile(ctx, volumeName, filePath, content, expected, overwrite, explorerImage)
+}
+
+// UploadVolumeFiles writes uploaded files into a directory of a volume
+func (m *Manager) UploadVolumeFiles(ctx context.Context, volumeName, dir string, files []FileUpload, overwrite bool, explorerImage string) ([]models.VolumeFileInfo, error) {
+	client, release := m.currentClient()
+	defer release()
+	return client.UploadVolumeFiles(ctx, volumeName, dir, files, overwrite, explorerImage)
+}
+
+// CreateVolumeDirectory creates a directory in a volume
+func (m *Manager) CreateVolumeDirectory(ctx context.Context, volumeName, dirPath, explorerImage string) (*models.VolumeFileInfo, error) {
+	client, release := m.currentClient()
+	defer release()
+	return client.CreateVolumeDirectory(ctx, volumeName, dirPath, explorerImage)
+}
+
+// MoveVolumeFile renames or moves a file or directory within a volume
+func (m *Manager) MoveVolumeFile(ctx context.Context, volumeName, from, to string, expected *FileVersion, explorerImage string) (*models.VolumeFileInfo, error) {
+	client, release := m.currentClient()
+	defer release()
+	return client.MoveVolumeFile(ctx, volumeName, from, to, expected, explorerImage)
+}
+
+// DeleteVolumeFile deletes a file or directory in a volume
+func (m *Manager) DeleteVolumeFile(ctx context.Context, volumeName, filePath string, recursive bool, expected *FileVersion, explorerImage string) error {
+	client, release := m.currentClient()
+	defer release()
+	return client.DeleteVolumeFile(ctx, volumeName, filePath, recursive, expected, explorerImage)
 }
 
 // Close closes the Docker client connection
 func (m *Manager) Close() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.explorer.Close()
 	if m.client != nil {
 		return m.client.Close()
 	}
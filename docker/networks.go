@@ -0,0 +1,228 @@
+package docker
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// builtinNetworks are created by Docker and cannot be removed
+var builtinNetworks = map[string]bool{"bridge": true, "host": true, "none": true}
+
+// CreateNetworkOptions holds the settings of a new network
+type CreateNetworkOptions struct {
+	Name       string
+	Driver     string // Defaults to "bridge"
+	Internal   bool
+	Attachable bool
+	EnableIPv6 bool
+	Subnet     string // CIDR, e.g. 172.28.0.0/16
+	Gateway    string
+	IPRange    string
+	Options    map[string]string
+	Labels     map[string]string
+}
+
+// ConnectNetworkOptions holds the settings of a container's endpoint on a network
+type ConnectNetworkOptions struct {
+	Aliases     []string
+	IPv4Address string // Static IPv4 address, requires a user-defined subnet
+	IPv6Address string
+}
+
+// networkContainers maps network IDs to the containers attached to them. Stopped
+// containers are included with their configured endpoint settings.
+func (c *Client) networkContainers(ctx context.Context) (map[string][]models.NetworkContainer, error) {
+	containers, err := c.cli.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]models.NetworkContainer)
+	for _, container := range containers {
+		if container.NetworkSettings == nil || isVolumeHelper(container) {
+			continue
+		}
+		name := ""
+		if len(container.Names) > 0 {
+			name = strings.TrimPrefix(container.Names[0], "/")
+		}
+		for _, endpoint := range container.NetworkSettings.Networks {
+			if endpoint == nil || endpoint.NetworkID == "" {
+				continue
+			}
+			aliases := []string{}
+			for _, alias := range endpoint.Aliases {
+				// Docker adds the short container ID as an alias on user-defined networks
+				if alias != container.ID[:shortIDLength] {
+					aliases = append(aliases, alias)
+				}
+			}
+			result[endpoint.NetworkID] = append(result[endpoint.NetworkID], models.NetworkContainer{
+				ID:          container.ID[:shortIDLength],
+				Name:        name,
+				State:       container.State,
+				IPv4Address: endpoint.IPAddress,
+				IPv6Address: endpoint.GlobalIPv6Address,
+				MacAddress:  endpoint.MacAddress,
+				Aliases:     aliases,
+			})
+		}
+	}
+
+	for _, attached := range result {
+		sort.Slice(attached, func(i, j int) bool {
+			return attached[i].Name < attached[j].Name
+		})
+	}
+	return result, nil
+}
+
+// ListNetworks lists all networks with the containers attached to them, sorted by name
+func (c *Client) ListNetworks(ctx context.Context) ([]models.Network, error) {
+	networks, err := c.cli.NetworkList(ctx, types.NetworkListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	attached, err := c.networkContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	result := []models.Network{}
+	for _, resource := range networks {
+		result = append(result, buildNetwork(resource, attached[resource.ID]))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// InspectNetwork gets a single network by ID or name
+func (c *Client) InspectNetwork(ctx context.Context, networkID string) (*models.Network, error) {
+	resource, err := c.cli.NetworkInspect(ctx, networkID, types.NetworkInspectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	attached, err := c.networkContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := buildNetwork(resource, attached[resource.ID])
+	return &result, nil
+}
+
+// CreateNetwork creates a network and returns its ID
+func (c *Client) CreateNetwork(ctx context.Context, opts CreateNetworkOptions) (string, error) {
+	driver := opts.Driver
+	if driver == "" {
+		driver = "bridge"
+	}
+
+	create := types.NetworkCreate{
+		CheckDuplicate: true,
+		Driver:         driver,
+		Internal:       opts.Internal,
+		Attachable:     opts.Attachable,
+		EnableIPv6:     opts.EnableIPv6,
+		Options:        opts.Options,
+		Labels:         opts.Labels,
+	}
+	if opts.Subnet != "" {
+		create.IPAM = &network.IPAM{
+			Driver: "default",
+			Config: []network.IPAMConfig{{
+				Subnet:  opts.Subnet,
+				Gateway: opts.Gateway,
+				IPRange: opts.IPRange,
+			}},
+		}
+	}
+
+	resp, err := c.cli.NetworkCreate(ctx, opts.Name, create)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// RemoveNetwork removes a network. Docker refuses to remove networks with attached containers.
+func (c *Client) RemoveNetwork(ctx context.Context, networkID string) error {
+	return c.cli.NetworkRemove(ctx, networkID)
+}
+
+// PruneNetworks removes all networks not used by any container and returns their names
+func (c *Client) PruneNetworks(ctx context.Context) ([]string, error) {
+	report, err := c.cli.NetworksPrune(ctx, filters.NewArgs())
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	deleted := []string{}
+	deleted = append(deleted, report.NetworksDeleted...)
+	return deleted, nil
+}
+
+// ConnectNetwork attaches a container to a network
+func (c *Client) ConnectNetwork(ctx context.Context, networkID, containerID string, opts ConnectNetworkOptions) error {
+	settings := &network.EndpointSettings{
+		Aliases: opts.Aliases,
+	}
+	if opts.IPv4Address != "" || opts.IPv6Address != "" {
+		settings.IPAMConfig = &network.EndpointIPAMConfig{
+			IPv4Address: opts.IPv4Address,
+			IPv6Address: opts.IPv6Address,
+		}
+	}
+	return c.cli.NetworkConnect(ctx, networkID, containerID, settings)
+}
+
+// DisconnectNetwork detaches a container from a network
+func (c *Client) DisconnectNetwork(ctx context.Context, networkID, containerID string, force bool) error {
+	return c.cli.NetworkDisconnect(ctx, networkID, containerID, force)
+}
+
+// buildNetwork converts a Docker network resource into the API model
+func buildNetwork(resource types.NetworkResource, containers []models.NetworkContainer) models.Network {
+	subnets := []models.NetworkSubnet{}
+	for _, config := range resource.IPAM.Config {
+		subnets = append(subnets, models.NetworkSubnet{
+			Subnet:  config.Subnet,
+			Gateway: config.Gateway,
+			IPRange: config.IPRange,
+		})
+	}
+	if containers == nil {
+		containers = []models.NetworkContainer{}
+	}
+
+	return models.Network{
+		ID:         resource.ID[:shortIDLength],
+		Name:       resource.Name,
+		Driver:     resource.Driver,
+		Scope:      resource.Scope,
+		Internal:   resource.Internal,
+		Attachable: resource.Attachable,
+		EnableIPv6: resource.EnableIPv6,
+		Builtin:    builtinNetworks[resource.Name],
+		Created:    resource.Created.Unix(),
+		Subnets:    subnets,
+		Options:    resource.Options,
+		Labels:     resource.Labels,
+		Containers: containers,
+	}
+}
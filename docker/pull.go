@@ -0,0 +1,177 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// ParseImageReference normalizes an image name with an optional tag or digest into a
+// pullable reference, e.g. ("nginx", "1.25", "") becomes "docker.io/library/nginx:1.25".
+// Without tag and digest, a tag or digest in the image name is kept and "latest" is assumed otherwise.
+func ParseImageReference(image, tag, digest string) (string, error) {
+	if tag != "" && digest != "" {
+		return "", errors.New("tag and digest cannot both be set")
+	}
+
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q: %w", image, err)
+	}
+
+	if tag == "" && digest == "" {
+		return reference.TagNameOnly(named).String(), nil
+	}
+
+	// An explicit tag or digest replaces whatever the image name carried
+	named = reference.TrimNamed(named)
+	if tag != "" {
+		tagged, err := reference.WithTag(named, tag)
+		if err != nil {
+			return "", fmt.Errorf("invalid tag %q: %w", tag, err)
+		}
+		return tagged.String(), nil
+	}
+
+	parsedDigest, err := reference.Parse(named.Name() + "@" + digest)
+	if err != nil {
+		return "", fmt.Errorf("invalid digest %q: %w", digest, err)
+	}
+	return parsedDigest.String(), nil
+}
+
+// layerDownloaded reports whether a layer status means all of its bytes have been downloaded
+func layerDownloaded(status string) bool {
+	switch status {
+	case "Download complete", "Verifying Checksum", "Extracting", "Pull complete":
+		return true
+	}
+	return false
+}
+
+// pullTracker aggregates Docker pull messages into per-layer progress
+type pullTracker struct {
+	progress models.ImagePullProgress
+	index    map[string]int
+	// downloaded holds the downloaded bytes per layer, kept separately because
+	// the layer's Current field is reused for the extraction phase
+	downloaded map[string]int64
+}
+
+func newPullTracker(ref string) *pullTracker {
+	return &pullTracker{
+		progress: models.ImagePullProgress{
+			Image:  ref,
+			Layers: []models.ImageLayerProgress{},
+		},
+		index:      make(map[string]int),
+		downloaded: make(map[string]int64),
+	}
+}
+
+// update applies a single pull message
+func (t *pullTracker) update(msg *jsonmessage.JSONMessage) {
+	// Messages without progress details are image-level status lines, except for
+	// layer state changes such as "Pulling fs layer" which carry the layer ID
+	if msg.ID == "" || strings.HasPrefix(msg.Status, "Pulling from") {
+		t.progress.Status = strings.TrimSpace(msg.Status)
+		if digest, ok := strings.CutPrefix(msg.Status, "Digest: "); ok {
+			t.progress.Digest = strings.TrimSpace(digest)
+		}
+		return
+	}
+
+	idx, ok := t.index[msg.ID]
+	if !ok {
+		idx = len(t.progress.Layers)
+		t.index[msg.ID] = idx
+		t.progress.Layers = append(t.progress.Layers, models.ImageLayerProgress{ID: msg.ID})
+	}
+
+	layer := &t.progress.Layers[idx]
+	layer.Status = msg.Status
+	if msg.Progress != nil {
+		layer.Current = msg.Progress.Current
+		if msg.Progress.Total > 0 {
+			layer.Total = msg.Progress.Total
+		}
+	}
+
+	switch {
+	case msg.Status == "Downloading":
+		t.downloaded[msg.ID] = layer.Current
+	case layerDownloaded(msg.Status):
+		t.downloaded[msg.ID] = layer.Total
+	}
+
+	t.progress.Downloaded, t.progress.Total = 0, 0
+	for _, l := range t.progress.Layers {
+		t.progress.Downloaded += t.downloaded[l.ID]
+		t.progress.Total += l.Total
+	}
+}
+
+// snapshot returns a copy of the current progress that is safe to hand to callbacks
+func (t *pullTracker) snapshot() *models.ImagePullProgress {
+	snapshot := t.progress
+	snapshot.Layers = append([]models.ImageLayerProgress{}, t.progress.Layers...)
+	return &snapshot
+}
+
+// PullImage pulls an image and reports the aggregated progress after every message
+// received from the daemon. The returned progress is marked done and carries the
+// manifest digest and local image ID.
+func (c *Client) PullImage(ctx context.Context, ref string, onProgress func(*models.ImagePullProgress) error) (*models.ImagePullProgress, error) {
+	reader, err := c.cli.ImagePull(ctx, ref, types.ImagePullOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	tracker := newPullTracker(ref)
+	decoder := json.NewDecoder(reader)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to read pull progress: %w", err)
+		}
+		if msg.Error != nil {
+			return nil, msg.Error
+		}
+
+		tracker.update(&msg)
+		if err := onProgress(tracker.snapshot()); err != nil {
+			return nil, err
+		}
+	}
+
+	result := tracker.snapshot()
+	result.Done = true
+
+	inspect, _, err := c.cli.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect pulled image: %w", err)
+	}
+	result.ImageID = inspect.ID
+	if result.Digest == "" {
+		// Pulls by digest do not always print a digest line; fall back to the
+		// repo digest recorded for the pulled repository
+		if digests := repoDigestsFor(ref, inspect.RepoDigests); len(digests) > 0 {
+			result.Digest = digests[0]
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,362 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+const (
+	// recreateStartupGrace is how long a container without a health check must keep
+	// running after a recreate before it is considered started
+	recreateStartupGrace = 5 * time.Second
+	// recreatePollInterval is how often the new container's state is checked
+	recreatePollInterval = time.Second
+)
+
+// ErrRecreateAutoRemove is returned when recreating a container that Docker removes once it stops
+var ErrRecreateAutoRemove = errors.New("cannot recreate a container with auto-remove enabled, it is removed as soon as it stops")
+
+// RecreateOptions controls how a container is recreated
+type RecreateOptions struct {
+	Pull          bool          // Pull the container's image before recreating
+	StopTimeout   int           // Seconds to wait for the old container to stop
+	HealthTimeout time.Duration // How long the new container has to become healthy
+}
+
+// RecreateContainer replaces a container with a new one created from the same settings,
+// optionally pulling a newer version of its image first. The old container is stopped and
+// renamed, the new container is created under the original name and started, and the old
+// container is removed once the new one is running (and healthy, if it has a health check).
+// If the new container fails to start or to become healthy within HealthTimeout, it is
+// removed and the old container is restored. The result is also returned on failure to
+// describe what happened. Containers with auto-remove enabled are rejected, as stopping them
+// would remove them before they can be restored.
+func (c *Client) RecreateContainer(ctx context.Context, containerID string, opts RecreateOptions) (*models.RecreateResult, error) {
+	old, err := c.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if old.HostConfig.AutoRemove {
+		return nil, ErrRecreateAutoRemove
+	}
+
+	name := strings.TrimPrefix(old.Name, "/")
+	result := &models.RecreateResult{
+		Name:           name,
+		Image:          old.Config.Image,
+		OldContainerID: old.ID[:shortIDLength],
+		OldImageID:     shortImageID(old.Image),
+	}
+
+	if opts.Pull {
+		if err := c.pullContainerImage(ctx, old.Config.Image); err != nil {
+			return result, err
+		}
+		result.Pulled = true
+	}
+
+	newImage, _, err := c.cli.ImageInspectWithRaw(ctx, old.Config.Image)
+	if err != nil {
+		return result, fmt.Errorf("failed to inspect image %s: %w", old.Config.Image, err)
+	}
+	result.NewImageID = shortImageID(newImage.ID)
+	result.ImageUpdated = newImage.ID != old.Image
+
+	config, hostConfig, networks, primary := c.recreateSpec(ctx, &old)
+	wasRunning := old.State.Running
+
+	if wasRunning {
+		if err := c.StopContainer(ctx, old.ID, opts.StopTimeout); err != nil {
+			return result, fmt.Errorf("failed to stop container: %w", err)
+		}
+	}
+
+	backupName := fmt.Sprintf("%s-old-%d", name, time.Now().Unix())
+	if err := c.cli.ContainerRename(ctx, old.ID, backupName); err != nil {
+		err = fmt.Errorf("failed to rename container: %w", err)
+		if wasRunning {
+			if startErr := c.StartContainer(context.WithoutCancel(ctx), old.ID); startErr != nil {
+				result.RollbackError = startErr.Error()
+			}
+		}
+		return result, err
+	}
+
+	newID, err := c.createAndStartReplacement(ctx, name, config, hostConfig, networks, primary)
+	if newID != "" {
+		result.NewContainerID = newID[:shortIDLength]
+	}
+	if err == nil {
+		err = c.waitContainerReady(ctx, newID, opts.HealthTimeout)
+	}
+	if err != nil {
+		c.rollbackRecreate(context.WithoutCancel(ctx), result, old.ID, newID, name, wasRunning)
+		return result, err
+	}
+
+	if err := c.cli.ContainerRemove(ctx, old.ID, types.ContainerRemoveOptions{}); err != nil {
+		// The new container is healthy; report the leftover instead of rolling back
+		return result, fmt.Errorf("container recreated but failed to remove old container %s: %w", backupName, err)
+	}
+
+	return result, nil
+}
+
+// pullContainerImage pulls the image a container was created from. Containers created
+// from an image ID cannot be pulled and are recreated from the same image.
+func (c *Client) pullContainerImage(ctx context.Context, image string) error {
+	if strings.HasPrefix(image, "sha256:") {
+		return fmt.Errorf("container was created from image ID %s, not a pullable reference", shortImageID(image))
+	}
+
+	ref, err := ParseImageReference(image, "", "")
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.PullImage(ctx, ref, func(*models.ImagePullProgress) error { return nil }); err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", ref, err)
+	}
+	return nil
+}
+
+// recreateSpec derives the settings of the replacement container from an inspected container.
+// Values the container inherited from its old image are dropped so that the new image's
+// defaults apply, and anonymous volumes are carried over so their data is kept.
+func (c *Client) recreateSpec(ctx context.Context, old *types.ContainerJSON) (*container.Config, *container.HostConfig, map[string]*network.EndpointSettings, string) {
+	config := *old.Config
+	hostConfig := *old.HostConfig
+
+	if oldImage, _, err := c.cli.ImageInspectWithRaw(ctx, old.Image); err == nil && oldImage.Config != nil {
+		stripImageDefaults(&config, oldImage.Config)
+	}
+
+	// Docker defaults the hostname to the short container ID
+	if config.Hostname == old.ID[:shortIDLength] {
+		config.Hostname = ""
+	}
+
+	volumeNames := make(map[string]string)
+	for _, m := range old.Mounts {
+		if m.Type == mount.TypeVolume && m.Name != "" {
+			volumeNames[m.Destination] = m.Name
+		}
+	}
+
+	// Anonymous volume mounts would get a new, empty volume; point them at the old volume instead
+	covered := make(map[string]bool)
+	for _, bind := range hostConfig.Binds {
+		parts := strings.Split(bind, ":")
+		if len(parts) >= 2 {
+			covered[parts[1]] = true
+		}
+	}
+	mounts := append([]mount.Mount{}, hostConfig.Mounts...)
+	for i, m := range mounts {
+		if m.Type == mount.TypeVolume && m.Source == "" {
+			mounts[i].Source = volumeNames[m.Target]
+		}
+		covered[m.Target] = true
+	}
+	hostConfig.Mounts = mounts
+
+	// Keep anonymous and image-declared volumes that are not part of the host config
+	binds := append([]string{}, hostConfig.Binds...)
+	for _, m := range old.Mounts {
+		if m.Type != mount.TypeVolume || m.Name == "" || covered[m.Destination] {
+			continue
+		}
+		bind := m.Name + ":" + m.Destination
+		if !m.RW {
+			bind += ":ro"
+		}
+		binds = append(binds, bind)
+	}
+	hostConfig.Binds = binds
+
+	// Networks are only copied for bridge and user-defined networks; host, none and
+	// container:<id> network modes are fully described by the host config
+	networks := make(map[string]*network.EndpointSettings)
+	primary := ""
+	mode := hostConfig.NetworkMode
+	if !mode.IsHost() && !mode.IsNone() && !mode.IsContainer() && old.NetworkSettings != nil {
+		primary = string(mode)
+		if mode.IsDefault() {
+			primary = "bridge"
+		}
+		for netName, endpoint := range old.NetworkSettings.Networks {
+			settings := &network.EndpointSettings{
+				IPAMConfig: endpoint.IPAMConfig,
+				Links:      endpoint.Links,
+				DriverOpts: endpoint.DriverOpts,
+			}
+			// Docker adds the short container ID as an alias on user-defined networks
+			for _, alias := range endpoint.Aliases {
+				if alias != old.ID[:shortIDLength] {
+					settings.Aliases = append(settings.Aliases, alias)
+				}
+			}
+			networks[netName] = settings
+		}
+	}
+
+	return &config, &hostConfig, networks, primary
+}
+
+// stripImageDefaults removes values a container config inherited from its image
+func stripImageDefaults(config, image *container.Config) {
+	imageEnv := make(map[string]bool, len(image.Env))
+	for _, env := range image.Env {
+		imageEnv[env] = true
+	}
+	env := []string{}
+	for _, e := range config.Env {
+		if !imageEnv[e] {
+			env = append(env, e)
+		}
+	}
+	config.Env = env
+
+	labels := make(map[string]string)
+	for k, v := range config.Labels {
+		if imageValue, ok := image.Labels[k]; !ok || imageValue != v {
+			labels[k] = v
+		}
+	}
+	config.Labels = labels
+
+	if strings.Join(config.Cmd, "\x00") == strings.Join(image.Cmd, "\x00") {
+		config.Cmd = nil
+	}
+	if strings.Join(config.Entrypoint, "\x00") == strings.Join(image.Entrypoint, "\x00") {
+		config.Entrypoint = nil
+	}
+	if config.WorkingDir == image.WorkingDir {
+		config.WorkingDir = ""
+	}
+	if config.User == image.User {
+		config.User = ""
+	}
+	if config.StopSignal == image.StopSignal {
+		config.StopSignal = ""
+	}
+	for port := range config.ExposedPorts {
+		if _, ok := image.ExposedPorts[port]; ok {
+			delete(config.ExposedPorts, port)
+		}
+	}
+	for path := range config.Volumes {
+		if _, ok := image.Volumes[path]; ok {
+			delete(config.Volumes, path)
+		}
+	}
+	if image.Healthcheck != nil && config.Healthcheck != nil &&
+		strings.Join(config.Healthcheck.Test, "\x00") == strings.Join(image.Healthcheck.Test, "\x00") {
+		config.Healthcheck = nil
+	}
+}
+
+// createAndStartReplacement creates the replacement container, connects it to its networks and
+// starts it. The ID is returned whenever the container was created, even if a later step failed.
+func (c *Client) createAndStartReplacement(ctx context.Context, name string, config *container.Config, hostConfig *container.HostConfig, networks map[string]*network.EndpointSettings, primary string) (string, error) {
+	// Older API versions only accept a single network at creation time
+	var networkingConfig *network.NetworkingConfig
+	if endpoint, ok := networks[primary]; ok {
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{primary: endpoint},
+		}
+	}
+
+	resp, err := c.cli.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	for netName, endpoint := range networks {
+		if netName == primary {
+			continue
+		}
+		if err := c.cli.NetworkConnect(ctx, netName, resp.ID, endpoint); err != nil {
+			return resp.ID, fmt.Errorf("failed to connect container to network %s: %w", netName, err)
+		}
+	}
+
+	if err := c.StartContainer(ctx, resp.ID); err != nil {
+		return resp.ID, fmt.Errorf("failed to start container: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// waitContainerReady waits until a started container is healthy or, without a health check,
+// has kept running for a short grace period
+func (c *Client) waitContainerReady(ctx context.Context, containerID string, healthTimeout time.Duration) error {
+	deadline := time.Now().Add(healthTimeout)
+	grace := recreateStartupGrace
+	if healthTimeout < grace {
+		grace = healthTimeout
+	}
+	graceEnd := time.Now().Add(grace)
+
+	for {
+		inspect, err := c.cli.ContainerInspect(ctx, containerID)
+		if err != nil {
+			return fmt.Errorf("failed to inspect new container: %w", err)
+		}
+		if !inspect.State.Running {
+			return fmt.Errorf("new container exited with code %d", inspect.State.ExitCode)
+		}
+
+		if inspect.State.Health == nil {
+			if !time.Now().Before(graceEnd) {
+				return nil
+			}
+		} else {
+			switch inspect.State.Health.Status {
+			case types.Healthy:
+				return nil
+			case types.Unhealthy:
+				return fmt.Errorf("new container is unhealthy")
+			}
+			if !time.Now().Before(deadline) {
+				return fmt.Errorf("new container did not become healthy within %s", healthTimeout)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(recreatePollInterval):
+		}
+	}
+}
+
+// rollbackRecreate removes the replacement container and restores the old one
+func (c *Client) rollbackRecreate(ctx context.Context, result *models.RecreateResult, oldID, newID, name string, wasRunning bool) {
+	result.RolledBack = true
+
+	var errs []string
+	if newID != "" {
+		if err := c.cli.ContainerRemove(ctx, newID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			errs = append(errs, "failed to remove new container: "+err.Error())
+		}
+	}
+	if err := c.cli.ContainerRename(ctx, oldID, name); err != nil {
+		errs = append(errs, "failed to restore container name: "+err.Error())
+	}
+	if wasRunning {
+		if err := c.StartContainer(ctx, oldID); err != nil {
+			errs = append(errs, "failed to start old container: "+err.Error())
+		}
+	}
+	result.RollbackError = strings.Join(errs, "; ")
+}
@@ -0,0 +1,729 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+	"github.com/dev-zapi/docker-simple-panel/stacks"
+)
+
+// Standard Docker Compose labels set on stack resources
+const (
+	composeContainerNumberLabel = "com.docker.compose.container-number"
+	composeOneoffLabel          = "com.docker.compose.oneoff"
+	composeConfigHashLabel      = "com.docker.compose.config-hash"
+	composeWorkingDirLabel      = "com.docker.compose.project.working_dir"
+	composeNetworkLabel         = "com.docker.compose.network"
+	composeVolumeLabel          = "com.docker.compose.volume"
+)
+
+// Stack action kinds
+const (
+	stackActionCreate    = "create"
+	stackActionRecreate  = "recreate"
+	stackActionStart     = "start"
+	stackActionRemove    = "remove"
+	stackActionUnchanged = "unchanged"
+)
+
+// stackStep is a planned change together with the function that applies it
+type stackStep struct {
+	action      models.StackAction
+	containerID string // Existing container affected by the step, if any
+	apply       func(ctx context.Context) error
+}
+
+// serviceSpec is the container definition derived from a compose service
+type serviceSpec struct {
+	containerName string
+	config        *container.Config
+	hostConfig    *container.HostConfig
+	networks      []string // Network names, the first one is used at creation time
+	endpoints     map[string]*network.EndpointSettings
+}
+
+// DeployStack creates or updates the networks, volumes and containers described by a compose file.
+// hostDir is the stack directory as seen by the Docker host; relative bind mounts are resolved
+// against it and rejected if it is empty. With dryRun set, the plan is computed but nothing is changed. Otherwise accepted, if set, is
+// called once the plan has been accepted and before anything is changed; if it fails, the
+// deployment is abandoned with its error. On failure the returned plan reports which actions
+// were applied before the error.
+func (m *Manager) DeployStack(ctx context.Context, name string, file *stacks.ComposeFile, hostDir string, stopTimeout int, dryRun bool, accepted func() error) (*models.StackPlan, error) {
+	client, release := m.currentClient()
+	defer release()
+
+	steps, err := client.planStackDeploy(ctx, name, file, hostDir, stopTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.runStackSteps(ctx, name, steps, dryRun, accepted)
+}
+
+// RemoveStack removes all containers and networks of a stack, and its volumes if removeVolumes is set
+func (m *Manager) RemoveStack(ctx context.Context, name string, removeVolumes bool, stopTimeout int, dryRun bool) (*models.StackPlan, error) {
+	client, release := m.currentClient()
+	defer release()
+
+	steps, err := client.planStackRemoval(ctx, name, removeVolumes, stopTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// Explorer helpers would keep the stack's volumes in use
+	if removeVolumes && !dryRun {
+		for _, step := range steps {
+			if step.action.Type == "volume" {
+				defer m.explorer.Suspend(step.action.Name)()
+			}
+		}
+	}
+
+	return m.runStackSteps(ctx, name, steps, dryRun, nil)
+}
+
+// runStackSteps applies planned steps in order, stopping at the first failure. accepted, if set,
+// is called before the first step is applied.
+func (m *Manager) runStackSteps(ctx context.Context, name string, steps []stackStep, dryRun bool, accepted func() error) (*models.StackPlan, error) {
+	plan := &models.StackPlan{
+		Name:    name,
+		DryRun:  dryRun,
+		Actions: []models.StackAction{},
+	}
+
+	// Refuse plans that would recreate or remove the container running this application
+	for _, step := range steps {
+		if step.containerID != "" && step.apply != nil && step.action.Action != stackActionStart && m.isSelfContainer(step.containerID) {
+			return nil, ErrSelfOperation
+		}
+	}
+
+	if !dryRun && accepted != nil {
+		if err := accepted(); err != nil {
+			return nil, err
+		}
+	}
+
+	var failure error
+	for _, step := range steps {
+		action := step.action
+		if !dryRun && failure == nil && step.apply != nil {
+			if err := step.apply(ctx); err != nil {
+				action.Error = err.Error()
+				failure = fmt.Errorf("%s %s %s: %w", action.Action, action.Type, action.Name, err)
+			} else {
+				action.Done = true
+			}
+		}
+		plan.Actions = append(plan.Actions, action)
+	}
+
+	return plan, failure
+}
+
+// planStackDeploy computes the steps needed to bring a project in line with a compose file
+func (c *Client) planStackDeploy(ctx context.Context, project string, file *stacks.ComposeFile, hostDir string, stopTimeout int) ([]stackStep, error) {
+	projectFilter := filters.NewArgs(filters.Arg("label", composeProjectLabel+"="+project))
+	var steps []stackStep
+
+	// Networks, including the implicit default network when a service uses it
+	networkNames := make(map[string]string)
+	desiredNetworks := make(map[string]bool)
+	networkKeys := make([]string, 0, len(file.Networks)+1)
+	for key := range file.Networks {
+		networkKeys = append(networkKeys, key)
+	}
+	if _, ok := file.Networks["default"]; !ok && usesDefaultNetwork(file) {
+		networkKeys = append(networkKeys, "default")
+	}
+	sort.Strings(networkKeys)
+
+	for _, key := range networkKeys {
+		def := file.Networks[key]
+		if def == nil {
+			def = &stacks.Network{}
+		}
+		name := resourceName(project, key, def.Name, def.External)
+		networkNames[key] = name
+		desiredNetworks[name] = true
+
+		_, err := c.cli.NetworkInspect(ctx, name, types.NetworkInspectOptions{})
+		exists := err == nil
+		if err != nil && !client.IsErrNotFound(err) {
+			return nil, fmt.Errorf("failed to inspect network %s: %w", name, err)
+		}
+
+		action := models.StackAction{Type: "network", Name: name, Action: stackActionUnchanged}
+		switch {
+		case def.External && !exists:
+			return nil, fmt.Errorf("external network %s not found", name)
+		case exists:
+			steps = append(steps, stackStep{action: action})
+		default:
+			action.Action = stackActionCreate
+			createOptions := types.NetworkCreate{
+				Driver:     def.Driver,
+				Options:    def.DriverOpts,
+				Internal:   def.Internal,
+				Attachable: def.Attachable,
+				Labels:     mergeLabels(def.Labels, map[string]string{composeProjectLabel: project, composeNetworkLabel: key}),
+			}
+			steps = append(steps, stackStep{action: action, apply: func(ctx context.Context) error {
+				_, err := c.cli.NetworkCreate(ctx, name, createOptions)
+				return err
+			}})
+		}
+	}
+
+	// Volumes are created when missing but never removed during a deployment
+	volumeNames := make(map[string]string)
+	volumeKeys := make([]string, 0, len(file.Volumes))
+	for key := range file.Volumes {
+		volumeKeys = append(volumeKeys, key)
+	}
+	sort.Strings(volumeKeys)
+
+	for _, key := range volumeKeys {
+		def := file.Volumes[key]
+		name := resourceName(project, key, def.Name, def.External)
+		volumeNames[key] = name
+
+		_, err := c.cli.VolumeInspect(ctx, name)
+		exists := err == nil
+		if err != nil && !client.IsErrNotFound(err) {
+			return nil, fmt.Errorf("failed to inspect volume %s: %w", name, err)
+		}
+
+		action := models.StackAction{Type: "volume", Name: name, Action: stackActionUnchanged}
+		switch {
+		case def.External && !exists:
+			return nil, fmt.Errorf("external volume %s not found", name)
+		case exists:
+			steps = append(steps, stackStep{action: action})
+		default:
+			action.Action = stackActionCreate
+			createOptions := volume.CreateOptions{
+				Name:       name,
+				Driver:     def.Driver,
+				DriverOpts: def.DriverOpts,
+				Labels:     mergeLabels(def.Labels, map[string]string{composeProjectLabel: project, composeVolumeLabel: key}),
+			}
+			steps = append(steps, stackStep{action: action, apply: func(ctx context.Context) error {
+				_, err := c.cli.VolumeCreate(ctx, createOptions)
+				return err
+			}})
+		}
+	}
+
+	// Existing containers of the project grouped by service
+	existing, err := c.cli.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: projectFilter})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project containers: %w", err)
+	}
+	byService := make(map[string][]types.Container)
+	for _, ctr := range existing {
+		service := ctr.Labels[composeServiceLabel]
+		byService[service] = append(byService[service], ctr)
+	}
+	for _, list := range byService {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Labels[composeContainerNumberLabel] < list[j].Labels[composeContainerNumberLabel]
+		})
+	}
+
+	// Remove containers of services that are no longer defined, and surplus replicas
+	serviceNames := make([]string, 0, len(byService))
+	for service := range byService {
+		serviceNames = append(serviceNames, service)
+	}
+	sort.Strings(serviceNames)
+	for _, service := range serviceNames {
+		list := byService[service]
+		keep := 0
+		if _, ok := file.Services[service]; ok {
+			keep = 1
+		}
+		for _, ctr := range list[keep:] {
+			steps = append(steps, c.removeContainerStep(ctr.ID, containerName(ctr.Names), service, "service no longer defined", stopTimeout))
+		}
+	}
+
+	// Create, recreate or start service containers in dependency order
+	order := make([]models.ComposeService, 0, len(file.Services))
+	for _, service := range file.ServiceNames() {
+		deps := make([]string, 0, len(file.Services[service].DependsOn))
+		for dep := range file.Services[service].DependsOn {
+			deps = append(deps, dep)
+		}
+		sort.Strings(deps)
+		order = append(order, models.ComposeService{Name: service, DependsOn: deps})
+	}
+
+	for _, ordered := range orderComposeServices(order) {
+		spec, err := buildServiceSpec(project, ordered.Name, file.Services[ordered.Name], networkNames, volumeNames, hostDir)
+		if err != nil {
+			return nil, err
+		}
+
+		action := models.StackAction{Type: "container", Name: spec.containerName, Service: ordered.Name}
+		list := byService[ordered.Name]
+		if len(list) == 0 {
+			action.Action = stackActionCreate
+			steps = append(steps, stackStep{action: action, apply: func(ctx context.Context) error {
+				if err := c.ensureServiceImage(ctx, spec); err != nil {
+					return err
+				}
+				_, err := c.createServiceContainer(ctx, spec, spec.containerName)
+				return err
+			}})
+			continue
+		}
+
+		current := list[0]
+		switch {
+		case current.Labels[composeConfigHashLabel] != spec.config.Labels[composeConfigHashLabel]:
+			action.Action = stackActionRecreate
+			action.Reason = "configuration changed"
+			steps = append(steps, stackStep{action: action, containerID: current.ID, apply: func(ctx context.Context) error {
+				return c.replaceServiceContainer(ctx, current, spec, stopTimeout)
+			}})
+		case current.State != "running":
+			action.Action = stackActionStart
+			action.Reason = "container is " + current.State
+			steps = append(steps, stackStep{action: action, containerID: current.ID, apply: func(ctx context.Context) error {
+				return c.cli.ContainerStart(ctx, current.ID, types.ContainerStartOptions{})
+			}})
+		default:
+			action.Action = stackActionUnchanged
+			steps = append(steps, stackStep{action: action, containerID: current.ID})
+		}
+	}
+
+	// Remove project networks that are no longer defined
+	existingNetworks, err := c.cli.NetworkList(ctx, types.NetworkListOptions{Filters: projectFilter})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project networks: %w", err)
+	}
+	for _, net := range existingNetworks {
+		if desiredNetworks[net.Name] {
+			continue
+		}
+		steps = append(steps, c.removeNetworkStep(net.ID, net.Name, "network no longer defined"))
+	}
+
+	return steps, nil
+}
+
+// planStackRemoval computes the steps needed to remove every resource of a project
+func (c *Client) planStackRemoval(ctx context.Context, project string, removeVolumes bool, stopTimeout int) ([]stackStep, error) {
+	projectFilter := filters.NewArgs(filters.Arg("label", composeProjectLabel+"="+project))
+	var steps []stackStep
+
+	containers, err := c.cli.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: projectFilter})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project containers: %w", err)
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		return containerName(containers[i].Names) < containerName(containers[j].Names)
+	})
+	for _, ctr := range containers {
+		steps = append(steps, c.removeContainerStep(ctr.ID, containerName(ctr.Names), ctr.Labels[composeServiceLabel], "", stopTimeout))
+	}
+
+	networks, err := c.cli.NetworkList(ctx, types.NetworkListOptions{Filters: projectFilter})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project networks: %w", err)
+	}
+	sort.Slice(networks, func(i, j int) bool {
+		return networks[i].Name < networks[j].Name
+	})
+	for _, net := range networks {
+		steps = append(steps, c.removeNetworkStep(net.ID, net.Name, ""))
+	}
+
+	if removeVolumes {
+		volumes, err := c.cli.VolumeList(ctx, volume.ListOptions{Filters: projectFilter})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list project volumes: %w", err)
+		}
+		sort.Slice(volumes.Volumes, func(i, j int) bool {
+			return volumes.Volumes[i].Name < volumes.Volumes[j].Name
+		})
+		for _, vol := range volumes.Volumes {
+			name := vol.Name
+			steps = append(steps, stackStep{
+				action: models.StackAction{Type: "volume", Name: name, Action: stackActionRemove},
+				apply: func(ctx context.Context) error {
+					return c.cli.VolumeRemove(ctx, name, false)
+				},
+			})
+		}
+	}
+
+	return steps, nil
+}
+
+// removeContainerStep plans stopping and removing a container
+func (c *Client) removeContainerStep(id, name, service, reason string, stopTimeout int) stackStep {
+	return stackStep{
+		action:      models.StackAction{Type: "container", Name: name, Service: service, Action: stackActionRemove, Reason: reason},
+		containerID: id,
+		apply: func(ctx context.Context) error {
+			if err := c.StopContainer(ctx, id, c.ResolveStopTimeout(ctx, id, nil, stopTimeout)); err != nil {
+				return err
+			}
+			return c.cli.ContainerRemove(ctx, id, types.ContainerRemoveOptions{})
+		},
+	}
+}
+
+// removeNetworkStep plans removing a network
+func (c *Client) removeNetworkStep(id, name, reason string) stackStep {
+	return stackStep{
+		action: models.StackAction{Type: "network", Name: name, Action: stackActionRemove, Reason: reason},
+		apply: func(ctx context.Context) error {
+			return c.cli.NetworkRemove(ctx, id)
+		},
+	}
+}
+
+// ensureServiceImage pulls the image of a service if it is not present
+func (c *Client) ensureServiceImage(ctx context.Context, spec *serviceSpec) error {
+	if _, _, err := c.cli.ImageInspectWithRaw(ctx, spec.config.Image); err != nil {
+		if !client.IsErrNotFound(err) {
+			return fmt.Errorf("failed to inspect image %s: %w", spec.config.Image, err)
+		}
+		pull, err := c.cli.ImagePull(ctx, spec.config.Image, types.ImagePullOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to pull image %s: %w", spec.config.Image, err)
+		}
+		_, err = io.Copy(io.Discard, pull)
+		pull.Close()
+		if err != nil {
+			return fmt.Errorf("failed to pull image %s: %w", spec.config.Image, err)
+		}
+	}
+	return nil
+}
+
+// createServiceContainer creates, connects and starts a service container. The ID is returned
+// whenever the container was created, even if a later step failed.
+func (c *Client) createServiceContainer(ctx context.Context, spec *serviceSpec, name string) (string, error) {
+	// Older API versions only accept a single network at creation time
+	var networkingConfig *network.NetworkingConfig
+	if len(spec.networks) > 0 {
+		first := spec.networks[0]
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{first: spec.endpoints[first]},
+		}
+	}
+
+	resp, err := c.cli.ContainerCreate(ctx, spec.config, spec.hostConfig, networkingConfig, nil, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container %s: %w", name, err)
+	}
+
+	for _, netName := range spec.networks[min(1, len(spec.networks)):] {
+		if err := c.cli.NetworkConnect(ctx, netName, resp.ID, spec.endpoints[netName]); err != nil {
+			return resp.ID, fmt.Errorf("failed to connect container %s to network %s: %w", name, netName, err)
+		}
+	}
+
+	if err := c.cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return resp.ID, fmt.Errorf("failed to start container %s: %w", name, err)
+	}
+	return resp.ID, nil
+}
+
+// replaceServiceContainer recreates a service container with new settings. The image is pulled
+// first, then the old container is stopped and renamed so the new one can take its name. The
+// old container is only removed once the new one has started; otherwise the new container is
+// removed and the old one is restored.
+func (c *Client) replaceServiceContainer(ctx context.Context, current types.Container, spec *serviceSpec, stopTimeout int) error {
+	if err := c.ensureServiceImage(ctx, spec); err != nil {
+		return err
+	}
+
+	// Keep the data of anonymous volumes, as Compose does, instead of starting with empty ones
+	spec = reuseAnonymousVolumes(spec, current.Mounts)
+
+	wasRunning := current.State == "running"
+	if err := c.StopContainer(ctx, current.ID, c.ResolveStopTimeout(ctx, current.ID, nil, stopTimeout)); err != nil {
+		return err
+	}
+
+	oldName := containerName(current.Names)
+	backupName := fmt.Sprintf("%s-old-%d", oldName, time.Now().Unix())
+	if err := c.cli.ContainerRename(ctx, current.ID, backupName); err != nil {
+		err = fmt.Errorf("failed to rename container %s: %w", oldName, err)
+		if wasRunning {
+			if startErr := c.StartContainer(context.WithoutCancel(ctx), current.ID); startErr != nil {
+				err = fmt.Errorf("%w; failed to restart old container: %v", err, startErr)
+			}
+		}
+		return err
+	}
+
+	newID, err := c.createServiceContainer(ctx, spec, spec.containerName)
+	if err != nil {
+		rollbackCtx := context.WithoutCancel(ctx)
+		var errs []string
+		if newID != "" {
+			if removeErr := c.cli.ContainerRemove(rollbackCtx, newID, types.ContainerRemoveOptions{Force: true}); removeErr != nil {
+				errs = append(errs, "failed to remove new container: "+removeErr.Error())
+			}
+		}
+		if renameErr := c.cli.ContainerRename(rollbackCtx, current.ID, oldName); renameErr != nil {
+			errs = append(errs, "failed to restore container name: "+renameErr.Error())
+		}
+		if wasRunning {
+			if startErr := c.StartContainer(rollbackCtx, current.ID); startErr != nil {
+				errs = append(errs, "failed to start old container: "+startErr.Error())
+			}
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("%w; rollback failed: %s", err, strings.Join(errs, "; "))
+		}
+		return fmt.Errorf("%w; old container restored", err)
+	}
+
+	if err := c.cli.ContainerRemove(ctx, current.ID, types.ContainerRemoveOptions{}); err != nil {
+		// The new container is running; report the leftover instead of rolling back
+		return fmt.Errorf("container recreated but failed to remove old container %s: %w", backupName, err)
+	}
+	return nil
+}
+
+// reuseAnonymousVolumes returns a copy of spec whose anonymous volumes use the volumes that the
+// given mounts of an existing container have at the same target
+func reuseAnonymousVolumes(spec *serviceSpec, mounts []types.MountPoint) *serviceSpec {
+	existing := make(map[string]string)
+	for _, mp := range mounts {
+		if mp.Type == mount.TypeVolume && mp.Name != "" {
+			existing[mp.Destination] = mp.Name
+		}
+	}
+
+	hostConfig := *spec.hostConfig
+	hostConfig.Mounts = append([]mount.Mount(nil), spec.hostConfig.Mounts...)
+	for i, mnt := range hostConfig.Mounts {
+		if mnt.Type == mount.TypeVolume && mnt.Source == "" {
+			hostConfig.Mounts[i].Source = existing[mnt.Target]
+		}
+	}
+
+	result := *spec
+	result.hostConfig = &hostConfig
+	return &result
+}
+
+// buildServiceSpec translates a compose service into Docker container settings. Relative bind
+// mounts are resolved against hostDir and rejected if it is empty.
+func buildServiceSpec(project, serviceName string, service *stacks.Service, networkNames, volumeNames map[string]string, hostDir string) (*serviceSpec, error) {
+	spec := &serviceSpec{
+		containerName: service.ContainerName,
+		endpoints:     make(map[string]*network.EndpointSettings),
+	}
+	if spec.containerName == "" {
+		spec.containerName = fmt.Sprintf("%s-%s-1", project, serviceName)
+	}
+
+	env := make([]string, 0, len(service.Environment))
+	for key, value := range service.Environment {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+
+	exposedPorts, portBindings, err := nat.ParsePortSpecs(service.Ports)
+	if err != nil {
+		return nil, fmt.Errorf("service %s: invalid ports: %w", serviceName, err)
+	}
+
+	restartPolicy, err := parseRestartPolicy(service.Restart)
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", serviceName, err)
+	}
+
+	spec.config = &container.Config{
+		Image:        service.Image,
+		Cmd:          []string(service.Command),
+		Entrypoint:   []string(service.Entrypoint),
+		Env:          env,
+		ExposedPorts: exposedPorts,
+		Hostname:     service.Hostname,
+		User:         service.User,
+		WorkingDir:   service.WorkingDir,
+		Labels:       mergeLabels(service.Labels, nil),
+	}
+	spec.hostConfig = &container.HostConfig{
+		PortBindings:  portBindings,
+		RestartPolicy: restartPolicy,
+		Privileged:    service.Privileged,
+	}
+
+	for _, vol := range service.Volumes {
+		switch {
+		case vol.Type == "bind":
+			source := vol.Source
+			if strings.HasPrefix(source, "~") {
+				return nil, fmt.Errorf("service %s: home-relative bind mount %s is not supported", serviceName, source)
+			}
+			if !filepath.IsAbs(source) {
+				if hostDir == "" {
+					return nil, fmt.Errorf("service %s: relative bind mount %s cannot be resolved on the Docker host, set docker.stacks_host_dir or use an absolute path", serviceName, source)
+				}
+				source = filepath.Join(hostDir, source)
+			}
+			// Binds (unlike Mounts) create a missing host directory, as Compose does
+			bind := source + ":" + vol.Target
+			if vol.ReadOnly {
+				bind += ":ro"
+			}
+			spec.hostConfig.Binds = append(spec.hostConfig.Binds, bind)
+		case vol.Source == "":
+			// Anonymous volume
+			spec.hostConfig.Mounts = append(spec.hostConfig.Mounts, mount.Mount{Type: mount.TypeVolume, Target: vol.Target, ReadOnly: vol.ReadOnly})
+		default:
+			spec.hostConfig.Mounts = append(spec.hostConfig.Mounts, mount.Mount{Type: mount.TypeVolume, Source: volumeNames[vol.Source], Target: vol.Target, ReadOnly: vol.ReadOnly})
+		}
+	}
+
+	attachments := service.Networks
+	if len(attachments) == 0 {
+		attachments = stacks.ServiceNetworks{"default": &stacks.ServiceNetwork{}}
+	}
+	for key, attachment := range attachments {
+		name := networkNames[key]
+		spec.networks = append(spec.networks, name)
+		endpoint := &network.EndpointSettings{
+			Aliases: append([]string{serviceName}, attachment.Aliases...),
+		}
+		if attachment.IPv4Address != "" {
+			endpoint.IPAMConfig = &network.EndpointIPAMConfig{IPv4Address: attachment.IPv4Address}
+		}
+		spec.endpoints[name] = endpoint
+	}
+	sort.Strings(spec.networks)
+
+	// Hash the resolved settings so configuration changes can be detected on the next deployment
+	hashInput, err := json.Marshal(struct {
+		Name       string
+		Config     *container.Config
+		HostConfig *container.HostConfig
+		Endpoints  map[string]*network.EndpointSettings
+	}{spec.containerName, spec.config, spec.hostConfig, spec.endpoints})
+	if err != nil {
+		return nil, fmt.Errorf("service %s: failed to hash configuration: %w", serviceName, err)
+	}
+	sum := sha256.Sum256(hashInput)
+
+	deps := make([]string, 0, len(service.DependsOn))
+	for dep, condition := range service.DependsOn {
+		deps = append(deps, dep+":"+condition+":false")
+	}
+	sort.Strings(deps)
+
+	spec.config.Labels[composeProjectLabel] = project
+	spec.config.Labels[composeServiceLabel] = serviceName
+	spec.config.Labels[composeContainerNumberLabel] = "1"
+	spec.config.Labels[composeOneoffLabel] = "False"
+	spec.config.Labels[composeConfigHashLabel] = hex.EncodeToString(sum[:])
+	if hostDir != "" {
+		spec.config.Labels[composeWorkingDirLabel] = hostDir
+	}
+	if len(deps) > 0 {
+		spec.config.Labels[composeDependsOnLabel] = strings.Join(deps, ",")
+	}
+
+	return spec, nil
+}
+
+// parseRestartPolicy parses a compose restart value such as "unless-stopped" or "on-failure:3"
+func parseRestartPolicy(value string) (container.RestartPolicy, error) {
+	if value == "" {
+		return container.RestartPolicy{}, nil
+	}
+
+	name, retries, hasRetries := strings.Cut(value, ":")
+	policy := container.RestartPolicy{Name: container.RestartPolicyMode(name)}
+	switch policy.Name {
+	case container.RestartPolicyDisabled, container.RestartPolicyAlways, container.RestartPolicyUnlessStopped:
+		if hasRetries {
+			return policy, fmt.Errorf("invalid restart policy %q", value)
+		}
+	case container.RestartPolicyOnFailure:
+		if hasRetries {
+			count, err := strconv.Atoi(retries)
+			if err != nil || count < 0 {
+				return policy, fmt.Errorf("invalid restart policy %q", value)
+			}
+			policy.MaximumRetryCount = count
+		}
+	default:
+		return policy, fmt.Errorf("invalid restart policy %q", value)
+	}
+	return policy, nil
+}
+
+// resourceName returns the Docker name of a compose network or volume
+func resourceName(project, key, explicitName string, external bool) string {
+	if explicitName != "" {
+		return explicitName
+	}
+	if external {
+		return key
+	}
+	return project + "_" + key
+}
+
+// usesDefaultNetwork reports whether any service is attached to the implicit default network
+func usesDefaultNetwork(file *stacks.ComposeFile) bool {
+	for _, service := range file.Services {
+		if len(service.Networks) == 0 {
+			return true
+		}
+		if _, ok := service.Networks["default"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeLabels copies user labels and adds the given system labels
+func mergeLabels(labels map[string]string, extra map[string]string) map[string]string {
+	result := make(map[string]string, len(labels)+len(extra))
+	for k, v := range labels {
+		result[k] = v
+	}
+	for k, v := range extra {
+		result[k] = v
+	}
+	return result
+}
+
+// containerName returns the primary name of a container without the leading slash
+func containerName(names []string) string {
+	if len(names) == 0 {
+		return "unknown"
+	}
+	return strings.TrimPrefix(names[0], "/")
+}
@@ -0,0 +1,406 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+	"github.com/dev-zapi/docker-simple-panel/stacks"
+)
+
+const testCompose = `
+services:
+  web:
+    image: nginx:1.25
+    ports: ["8080:80"]
+    volumes:
+      - data:/data
+      - ./conf:/etc/nginx/conf.d:ro
+      - /cache
+    depends_on: [db]
+    restart: unless-stopped
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_PASSWORD: secret
+volumes:
+  data:
+`
+
+func parseTestCompose(t *testing.T, content string) *stacks.ComposeFile {
+	t.Helper()
+	file, err := stacks.Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return file
+}
+
+func TestBuildServiceSpec(t *testing.T) {
+	file := parseTestCompose(t, testCompose)
+	networks := map[string]string{"default": "app_default"}
+	volumes := map[string]string{"data": "app_data"}
+
+	spec, err := buildServiceSpec("app", "web", file.Services["web"], networks, volumes, "/srv/stacks/app")
+	if err != nil {
+		t.Fatalf("buildServiceSpec() error = %v", err)
+	}
+
+	if spec.containerName != "app-web-1" {
+		t.Errorf("containerName = %q, want app-web-1", spec.containerName)
+	}
+	if want := []string{"/srv/stacks/app/conf:/etc/nginx/conf.d:ro"}; !reflect.DeepEqual(spec.hostConfig.Binds, want) {
+		t.Errorf("Binds = %q, want %q", spec.hostConfig.Binds, want)
+	}
+	wantMounts := []mount.Mount{
+		{Type: mount.TypeVolume, Source: "app_data", Target: "/data"},
+		{Type: mount.TypeVolume, Target: "/cache"},
+	}
+	if !reflect.DeepEqual(spec.hostConfig.Mounts, wantMounts) {
+		t.Errorf("Mounts = %+v, want %+v", spec.hostConfig.Mounts, wantMounts)
+	}
+	if bindings := spec.hostConfig.PortBindings["80/tcp"]; len(bindings) != 1 || bindings[0].HostPort != "8080" {
+		t.Errorf("PortBindings = %v, want 80/tcp published on 8080", spec.hostConfig.PortBindings)
+	}
+	if spec.hostConfig.RestartPolicy.Name != "unless-stopped" {
+		t.Errorf("RestartPolicy = %v, want unless-stopped", spec.hostConfig.RestartPolicy)
+	}
+	if want := []string{"app_default"}; !reflect.DeepEqual(spec.networks, want) {
+		t.Errorf("networks = %q, want %q", spec.networks, want)
+	}
+	if want := []string{"web"}; !reflect.DeepEqual(spec.endpoints["app_default"].Aliases, want) {
+		t.Errorf("aliases = %q, want %q", spec.endpoints["app_default"].Aliases, want)
+	}
+
+	labels := spec.config.Labels
+	wantLabels := map[string]string{
+		composeProjectLabel:    "app",
+		composeServiceLabel:    "web",
+		composeWorkingDirLabel: "/srv/stacks/app",
+		composeDependsOnLabel:  "db:service_started:false",
+	}
+	for key, want := range wantLabels {
+		if labels[key] != want {
+			t.Errorf("label %s = %q, want %q", key, labels[key], want)
+		}
+	}
+	if labels[composeConfigHashLabel] == "" {
+		t.Error("config hash label is missing")
+	}
+}
+
+func TestBuildServiceSpecConfigHash(t *testing.T) {
+	networks := map[string]string{"default": "app_default"}
+	hash := func(content, hostDir string) string {
+		t.Helper()
+		file := parseTestCompose(t, content)
+		spec, err := buildServiceSpec("app", "db", file.Services["db"], networks, nil, hostDir)
+		if err != nil {
+			t.Fatalf("buildServiceSpec() error = %v", err)
+		}
+		return spec.config.Labels[composeConfigHashLabel]
+	}
+
+	base := hash("services:\n  db:\n    image: postgres:16\n    environment: [A=1, B=2]\n", "/srv/a")
+	if got := hash("services:\n  db:\n    image: postgres:16\n    environment: {B: 2, A: 1}\n", "/srv/b"); got != base {
+		t.Error("config hash changed for an equivalent service")
+	}
+	if got := hash("services:\n  db:\n    image: postgres:17\n    environment: [A=1, B=2]\n", "/srv/a"); got == base {
+		t.Error("config hash did not change with the image")
+	}
+}
+
+func TestBuildServiceSpecBinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		volume  string
+		hostDir string
+		want    string
+		wantErr string
+	}{
+		{name: "relative", volume: "./data:/data", hostDir: "/srv/app", want: "/srv/app/data:/data"},
+		{name: "parent", volume: "../shared:/shared:ro", hostDir: "/srv/app", want: "/srv/shared:/shared:ro"},
+		{name: "absolute", volume: "/var/log:/logs", want: "/var/log:/logs"},
+		{name: "relative without host directory", volume: "./data:/data", wantErr: "stacks_host_dir"},
+		{name: "home relative", volume: "~/data:/data", hostDir: "/srv/app", wantErr: "not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := parseTestCompose(t, "services:\n  web:\n    image: nginx\n    volumes: [\""+tt.volume+"\"]\n")
+			spec, err := buildServiceSpec("app", "web", file.Services["web"], map[string]string{"default": "app_default"}, nil, tt.hostDir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("buildServiceSpec() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildServiceSpec() error = %v", err)
+			}
+			if want := []string{tt.want}; !reflect.DeepEqual(spec.hostConfig.Binds, want) {
+				t.Errorf("Binds = %q, want %q", spec.hostConfig.Binds, want)
+			}
+		})
+	}
+}
+
+func TestBuildServiceSpecInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		wantErr string
+	}{
+		{name: "restart policy", service: "restart: sometimes", wantErr: "invalid restart policy"},
+		{name: "restart retries", service: "restart: always:3", wantErr: "invalid restart policy"},
+		{name: "ports", service: "ports: [\"80:abc\"]", wantErr: "invalid ports"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := parseTestCompose(t, "services:\n  web:\n    image: nginx\n    "+tt.service+"\n")
+			_, err := buildServiceSpec("app", "web", file.Services["web"], map[string]string{"default": "app_default"}, nil, "/srv/app")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("buildServiceSpec() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReuseAnonymousVolumes(t *testing.T) {
+	file := parseTestCompose(t, testCompose)
+	spec, err := buildServiceSpec("app", "web", file.Services["web"], map[string]string{"default": "app_default"}, map[string]string{"data": "app_data"}, "/srv/app")
+	if err != nil {
+		t.Fatalf("buildServiceSpec() error = %v", err)
+	}
+
+	mounts := []types.MountPoint{
+		{Type: mount.TypeVolume, Name: "app_data", Destination: "/data"},
+		{Type: mount.TypeVolume, Name: "3f1c9a", Destination: "/cache"},
+		{Type: mount.TypeBind, Source: "/srv/app/conf", Destination: "/etc/nginx/conf.d"},
+	}
+	reused := reuseAnonymousVolumes(spec, mounts)
+
+	want := []mount.Mount{
+		{Type: mount.TypeVolume, Source: "app_data", Target: "/data"},
+		{Type: mount.TypeVolume, Source: "3f1c9a", Target: "/cache"},
+	}
+	if !reflect.DeepEqual(reused.hostConfig.Mounts, want) {
+		t.Errorf("Mounts = %+v, want %+v", reused.hostConfig.Mounts, want)
+	}
+	if spec.hostConfig.Mounts[1].Source != "" {
+		t.Error("reuseAnonymousVolumes modified the original spec")
+	}
+}
+
+// fakeDaemon serves the read-only Docker API endpoints used to plan a deployment
+type fakeDaemon struct {
+	networks   []types.NetworkResource
+	volumes    []string
+	containers []types.Container
+}
+
+func (d *fakeDaemon) client(t *testing.T) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("planning sent %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		path := r.URL.Path[strings.Index(r.URL.Path[1:], "/")+1:] // Strip the API version
+		switch {
+		case path == "/containers/json":
+			json.NewEncoder(w).Encode(d.containers)
+			return
+		case path == "/networks":
+			json.NewEncoder(w).Encode(d.networks)
+			return
+		case strings.HasPrefix(path, "/networks/"):
+			name := strings.TrimPrefix(path, "/networks/")
+			for _, net := range d.networks {
+				if net.Name == name {
+					json.NewEncoder(w).Encode(net)
+					return
+				}
+			}
+		case strings.HasPrefix(path, "/volumes/"):
+			name := strings.TrimPrefix(path, "/volumes/")
+			for _, vol := range d.volumes {
+				if vol == name {
+					json.NewEncoder(w).Encode(volume.Volume{Name: vol})
+					return
+				}
+			}
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "not found"})
+	}))
+	t.Cleanup(server.Close)
+
+	cli, err := client.NewClientWithOpts(client.WithHost(server.URL), client.WithVersion("1.43"), client.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{cli: cli}
+}
+
+// planActions returns the planned actions as "action type name" strings
+func planActions(steps []stackStep) []string {
+	actions := make([]string, 0, len(steps))
+	for _, step := range steps {
+		actions = append(actions, step.action.Action+" "+step.action.Type+" "+step.action.Name)
+	}
+	return actions
+}
+
+func TestPlanStackDeploy(t *testing.T) {
+	file := parseTestCompose(t, testCompose)
+	networks := map[string]string{"default": "app_default"}
+	volumes := map[string]string{"data": "app_data"}
+	specHash := func(service string) string {
+		spec, err := buildServiceSpec("app", service, file.Services[service], networks, volumes, "/srv/app")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return spec.config.Labels[composeConfigHashLabel]
+	}
+	serviceContainer := func(id, service, state, hash string) types.Container {
+		return types.Container{
+			ID:    id,
+			Names: []string{"/app-" + service + "-1"},
+			State: state,
+			Labels: map[string]string{
+				composeProjectLabel:         "app",
+				composeServiceLabel:         service,
+				composeContainerNumberLabel: "1",
+				composeConfigHashLabel:      hash,
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		daemon fakeDaemon
+		want   []string
+	}{
+		{
+			name: "new stack",
+			want: []string{
+				"create network app_default",
+				"create volume app_data",
+				"create container app-db-1",
+				"create container app-web-1",
+			},
+		},
+		{
+			name: "unchanged stack",
+			daemon: fakeDaemon{
+				networks: []types.NetworkResource{{ID: "n1", Name: "app_default"}},
+				volumes:  []string{"app_data"},
+				containers: []types.Container{
+					serviceContainer("c1", "db", "running", specHash("db")),
+					serviceContainer("c2", "web", "running", specHash("web")),
+				},
+			},
+			want: []string{
+				"unchanged network app_default",
+				"unchanged volume app_data",
+				"unchanged container app-db-1",
+				"unchanged container app-web-1",
+			},
+		},
+		{
+			name: "changed, stopped and removed services",
+			daemon: fakeDaemon{
+				networks: []types.NetworkResource{{ID: "n1", Name: "app_default"}, {ID: "n2", Name: "app_old"}},
+				volumes:  []string{"app_data"},
+				containers: []types.Container{
+					serviceContainer("c1", "db", "exited", specHash("db")),
+					serviceContainer("c2", "web", "running", "outdated"),
+					serviceContainer("c3", "worker", "running", "any"),
+				},
+			},
+			want: []string{
+				"unchanged network app_default",
+				"unchanged volume app_data",
+				"remove container app-worker-1",
+				"start container app-db-1",
+				"recreate container app-web-1",
+				"remove network app_old",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.daemon.client(t)
+			steps, err := c.planStackDeploy(context.Background(), "app", file, "/srv/app", 10)
+			if err != nil {
+				t.Fatalf("planStackDeploy() error = %v", err)
+			}
+			if got := planActions(steps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("plan = %q, want %q", got, tt.want)
+			}
+			for _, step := range steps {
+				if (step.action.Action == stackActionUnchanged) != (step.apply == nil) {
+					t.Errorf("step %s %s has apply set to %v", step.action.Action, step.action.Name, step.apply != nil)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanStackDeployMissingExternalNetwork(t *testing.T) {
+	file := parseTestCompose(t, "services:\n  web:\n    image: nginx\n    networks: [proxy]\nnetworks:\n  proxy:\n    external: true\n")
+	c := (&fakeDaemon{}).client(t)
+
+	_, err := c.planStackDeploy(context.Background(), "app", file, "/srv/app", 10)
+	if err == nil || !strings.Contains(err.Error(), "external network proxy not found") {
+		t.Fatalf("planStackDeploy() error = %v, want missing external network", err)
+	}
+}
+
+func TestRunStackStepsStopsAtFirstFailure(t *testing.T) {
+	var applied []string
+	step := func(name string, err error) stackStep {
+		return stackStep{
+			action: models.StackAction{Type: "container", Name: name, Action: stackActionCreate},
+			apply: func(ctx context.Context) error {
+				applied = append(applied, name)
+				return err
+			},
+		}
+	}
+	steps := []stackStep{step("a", nil), step("b", context.DeadlineExceeded), step("c", nil)}
+
+	m := &Manager{}
+	plan, err := m.runStackSteps(context.Background(), "app", steps, false, nil)
+	if err == nil {
+		t.Fatal("runStackSteps() error = nil, want failure of b")
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied = %q, want %q", applied, want)
+	}
+	if !plan.Actions[0].Done || plan.Actions[1].Done || plan.Actions[1].Error == "" || plan.Actions[2].Done {
+		t.Errorf("actions = %+v, want only a done and b failed", plan.Actions)
+	}
+
+	applied = nil
+	if _, err := m.runStackSteps(context.Background(), "app", steps, true, nil); err != nil || len(applied) != 0 {
+		t.Errorf("dry run applied %q, error = %v", applied, err)
+	}
+}
@@ -0,0 +1,123 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// ContainerStats gets a single resource usage sample for a container.
+// The daemon collects two samples so that the CPU percentage can be computed.
+func (c *Client) ContainerStats(ctx context.Context, containerID string) (*models.ContainerStats, error) {
+	resp, err := c.cli.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var raw types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode container stats: %w", err)
+	}
+
+	return calculateStats(&raw), nil
+}
+
+// StreamContainerStats streams resource usage samples for a container, calling onSample
+// for each one until the context is cancelled, the container stops or onSample returns an error
+func (c *Client) StreamContainerStats(ctx context.Context, containerID string, onSample func(*models.ContainerStats) error) error {
+	resp, err := c.cli.ContainerStats(ctx, containerID, true)
+	if err != nil {
+		return fmt.Errorf("failed to get container stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var raw types.StatsJSON
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to decode container stats: %w", err)
+		}
+
+		if err := onSample(calculateStats(&raw)); err != nil {
+			return err
+		}
+	}
+}
+
+// calculateStats converts a raw Docker stats sample into the values shown by `docker stats`
+func calculateStats(raw *types.StatsJSON) *models.ContainerStats {
+	id := raw.ID
+	if len(id) > shortIDLength {
+		id = id[:shortIDLength]
+	}
+
+	stats := &models.ContainerStats{
+		ID:          id,
+		Name:        strings.TrimPrefix(raw.Name, "/"),
+		Read:        raw.Read.Format(time.RFC3339Nano),
+		CPUPercent:  calculateCPUPercent(raw),
+		MemoryLimit: raw.MemoryStats.Limit,
+		PIDs:        raw.PidsStats.Current,
+	}
+
+	// Exclude page cache from memory usage, like the Docker CLI does.
+	// cgroup v1 reports it as total_inactive_file, cgroup v2 as inactive_file.
+	usage := raw.MemoryStats.Usage
+	cache, ok := raw.MemoryStats.Stats["total_inactive_file"]
+	if !ok {
+		cache = raw.MemoryStats.Stats["inactive_file"]
+	}
+	if cache < usage {
+		usage -= cache
+	}
+	stats.MemoryUsage = usage
+	if stats.MemoryLimit > 0 {
+		stats.MemoryPercent = float64(usage) / float64(stats.MemoryLimit) * 100.0
+	}
+
+	for _, network := range raw.Networks {
+		stats.NetworkRx += network.RxBytes
+		stats.NetworkTx += network.TxBytes
+	}
+
+	for _, entry := range raw.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			stats.BlockRead += entry.Value
+		case "write":
+			stats.BlockWrite += entry.Value
+		}
+	}
+
+	return stats
+}
+
+// calculateCPUPercent computes the CPU usage percentage between the previous and current sample
+func calculateCPUPercent(raw *types.StatsJSON) float64 {
+	cpuDelta := float64(raw.CPUStats.CPUUsage.TotalUsage) - float64(raw.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(raw.CPUStats.SystemUsage) - float64(raw.PreCPUStats.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	onlineCPUs := float64(raw.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(raw.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if onlineCPUs == 0 {
+		onlineCPUs = 1
+	}
+
+	return cpuDelta / systemDelta * onlineCPUs * 100.0
+}
@@ -0,0 +1,134 @@
+package docker
+
+import (
+	"context"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+const (
+	// TopologyContainerNode is the node type of containers in the topology graph
+	TopologyContainerNode = "container"
+	// TopologyNetworkNode is the node type of networks in the topology graph
+	TopologyNetworkNode = "network"
+)
+
+// topologyNodeID builds the graph node ID of a container or network
+func topologyNodeID(nodeType, id string) string {
+	if len(id) > shortIDLength {
+		id = id[:shortIDLength]
+	}
+	return nodeType + ":" + id
+}
+
+// Topology builds a graph of all containers and networks. Edges carry the container's
+// addresses and aliases on the network; container nodes carry their published host ports.
+func (c *Client) Topology(ctx context.Context) (*models.Topology, error) {
+	networks, err := c.cli.NetworkList(ctx, types.NetworkListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	containers, err := c.cli.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize as empty slices to ensure JSON marshals to [] instead of null
+	topology := &models.Topology{
+		Nodes: []models.TopologyNode{},
+		Edges: []models.TopologyEdge{},
+	}
+
+	sort.Slice(networks, func(i, j int) bool {
+		return networks[i].Name < networks[j].Name
+	})
+	networkIDs := make(map[string]string, len(networks))
+	for _, resource := range networks {
+		networkIDs[resource.Name] = resource.ID
+		subnets := []string{}
+		for _, config := range resource.IPAM.Config {
+			subnets = append(subnets, config.Subnet)
+		}
+		topology.Nodes = append(topology.Nodes, models.TopologyNode{
+			ID:       topologyNodeID(TopologyNetworkNode, resource.ID),
+			Type:     TopologyNetworkNode,
+			Name:     resource.Name,
+			Driver:   resource.Driver,
+			Internal: resource.Internal,
+			Subnets:  subnets,
+		})
+	}
+
+	infos := make([]*models.ContainerInfo, 0, len(containers))
+	for _, container := range containers {
+		if isVolumeHelper(container) {
+			continue
+		}
+		info, err := c.GetContainerInfo(ctx, container.ID)
+		if err != nil {
+			// The container may have been removed since it was listed
+			if client.IsErrNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	for _, info := range infos {
+		nodeID := topologyNodeID(TopologyContainerNode, info.ID)
+
+		ports := []models.PortBinding{}
+		for _, port := range info.Ports {
+			if port.HostPort != "" {
+				ports = append(ports, port)
+			}
+		}
+		sort.Slice(ports, func(i, j int) bool {
+			return ports[i].ContainerPort < ports[j].ContainerPort
+		})
+
+		topology.Nodes = append(topology.Nodes, models.TopologyNode{
+			ID:             nodeID,
+			Type:           TopologyContainerNode,
+			Name:           info.Name,
+			State:          info.State,
+			Image:          info.Image,
+			ComposeProject: info.ComposeProject,
+			Ports:          ports,
+		})
+
+		names := make([]string, 0, len(info.Networks))
+		for name := range info.Networks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			endpoint := info.Networks[name]
+			networkID := endpoint.NetworkID
+			if networkID == "" {
+				networkID = networkIDs[name]
+			}
+			if networkID == "" {
+				continue
+			}
+			topology.Edges = append(topology.Edges, models.TopologyEdge{
+				Source:      nodeID,
+				Target:      topologyNodeID(TopologyNetworkNode, networkID),
+				IPv4Address: endpoint.IPAddress,
+				IPv6Address: endpoint.IPv6Address,
+				MacAddress:  endpoint.MacAddress,
+				Aliases:     endpoint.Aliases,
+			})
+		}
+	}
+
+	return topology, nil
+}
@@ -0,0 +1,231 @@
+package docker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+	"github.com/dev-zapi/docker-simple-panel/registry"
+)
+
+// UpdateCheckLabel opts a container out of image update checks when set to "false"
+const UpdateCheckLabel = "dsp.update-check"
+
+const (
+	// imageUpdateCacheTTL is how long a remote digest lookup is reused
+	imageUpdateCacheTTL = time.Hour
+	// maxConcurrentRegistryChecks bounds the number of parallel registry lookups
+	maxConcurrentRegistryChecks = 4
+)
+
+// remoteDigest is a cached registry lookup
+type remoteDigest struct {
+	digest    string
+	err       error
+	checkedAt time.Time
+}
+
+// UpdateChecker compares container images with the manifests their tags point to in the registry
+type UpdateChecker struct {
+	registry *registry.Client
+	mu       sync.Mutex
+	remote   map[string]remoteDigest // Normalized reference -> lookup
+	// available caches the last computed result per reference and local image ID,
+	// used to annotate container listings without contacting registries
+	available map[string]bool
+}
+
+// NewUpdateChecker creates an update checker that resolves tags with the given registry client
+func NewUpdateChecker(registryClient *registry.Client) *UpdateChecker {
+	return &UpdateChecker{
+		registry:  registryClient,
+		remote:    make(map[string]remoteDigest),
+		available: make(map[string]bool),
+	}
+}
+
+// SetRegistryHosts replaces the registry credentials and clears cached lookups
+func (u *UpdateChecker) SetRegistryHosts(hosts map[string]registry.Host) {
+	u.registry.SetHosts(hosts)
+
+	u.mu.Lock()
+	u.remote = make(map[string]remoteDigest)
+	u.available = make(map[string]bool)
+	u.mu.Unlock()
+}
+
+// lookup returns the remote digest of a reference, from the cache unless it expired or refresh is set
+func (u *UpdateChecker) lookup(ctx context.Context, ref string, refresh bool) remoteDigest {
+	u.mu.Lock()
+	cached, ok := u.remote[ref]
+	u.mu.Unlock()
+	if ok && !refresh && time.Since(cached.checkedAt) < imageUpdateCacheTTL {
+		return cached
+	}
+
+	digest, err := u.registry.ManifestDigest(ctx, ref)
+	result := remoteDigest{digest: digest, err: err, checkedAt: time.Now()}
+	if ctx.Err() == nil {
+		u.mu.Lock()
+		u.remote[ref] = result
+		u.mu.Unlock()
+	}
+	return result
+}
+
+// updateAvailable reports the result of the last check for an image reference and short
+// image ID. It never contacts registries; images that were not checked report false.
+func (u *UpdateChecker) updateAvailable(image, imageID string) bool {
+	ref, err := ParseImageReference(image, "", "")
+	if err != nil {
+		return false
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.available[ref+"|"+imageID]
+}
+
+// pendingUpdate is a result waiting for the remote digest of its image reference
+type pendingUpdate struct {
+	result  int      // Index of the result
+	digests []string // Registry digests of the container's local image
+}
+
+// Check resolves the update status of every running container. Containers whose image is
+// pinned to a digest, has no registry digest (built or loaded locally), or that carry
+// UpdateCheckLabel=false are reported as skipped.
+func (u *UpdateChecker) Check(ctx context.Context, c *Client, refresh bool) ([]models.ImageUpdateStatus, error) {
+	containers, err := c.cli.ContainerList(ctx, types.ContainerListOptions{
+		Filters: filters.NewArgs(filters.Arg("status", "running")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	results := []models.ImageUpdateStatus{}
+	localDigests := make(map[string][]string)
+	pending := make(map[string][]pendingUpdate) // Reference -> results waiting for its remote digest
+
+	for _, container := range containers {
+		if isVolumeHelper(container) {
+			continue
+		}
+		name := ""
+		if len(container.Names) > 0 {
+			name = strings.TrimPrefix(container.Names[0], "/")
+		}
+		status := models.ImageUpdateStatus{
+			ContainerID:   container.ID[:shortIDLength],
+			ContainerName: name,
+			Image:         container.Image,
+			ImageID:       shortImageID(container.ImageID),
+		}
+
+		if container.Labels[UpdateCheckLabel] == "false" {
+			status.Skipped = "update checks disabled by label " + UpdateCheckLabel
+			results = append(results, status)
+			continue
+		}
+
+		named, err := reference.ParseNormalizedNamed(container.Image)
+		if err != nil {
+			status.Skipped = "image is not referenced by name"
+			results = append(results, status)
+			continue
+		}
+		if _, ok := named.(reference.Canonical); ok {
+			status.Skipped = "image is pinned to a digest"
+			results = append(results, status)
+			continue
+		}
+		ref := reference.TagNameOnly(named).String()
+
+		digests, ok := localDigests[container.ImageID]
+		if !ok {
+			inspect, _, err := c.cli.ImageInspectWithRaw(ctx, container.ImageID)
+			if err != nil {
+				status.Error = "failed to inspect image: " + err.Error()
+				results = append(results, status)
+				continue
+			}
+			digests = repoDigestsFor(ref, inspect.RepoDigests)
+			localDigests[container.ImageID] = digests
+		}
+		if len(digests) == 0 {
+			status.Skipped = "image has no registry digest (built or loaded locally)"
+			results = append(results, status)
+			continue
+		}
+		status.LocalDigest = digests[0]
+
+		pending[ref] = append(pending[ref], pendingUpdate{result: len(results), digests: digests})
+		results = append(results, status)
+	}
+
+	// Resolve each reference once, in parallel
+	lookups := make(map[string]remoteDigest, len(pending))
+	var wg sync.WaitGroup
+	var lookupsMu sync.Mutex
+	sem := make(chan struct{}, maxConcurrentRegistryChecks)
+	for ref := range pending {
+		wg.Add(1)
+		go func(ref string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := u.lookup(ctx, ref, refresh)
+			lookupsMu.Lock()
+			lookups[ref] = result
+			lookupsMu.Unlock()
+		}(ref)
+	}
+	wg.Wait()
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for ref, updates := range pending {
+		lookup := lookups[ref]
+		for _, update := range updates {
+			status := &results[update.result]
+			status.CheckedAt = lookup.checkedAt.Unix()
+			if lookup.err != nil {
+				status.Error = lookup.err.Error()
+				continue
+			}
+			status.RemoteDigest = lookup.digest
+			status.UpdateAvailable = !containsString(update.digests, lookup.digest)
+			u.available[ref+"|"+status.ImageID] = status.UpdateAvailable
+		}
+	}
+
+	return results, nil
+}
+
+// repoDigestsFor returns the digests recorded for the repository of ref
+func repoDigestsFor(ref string, repoDigests []string) []string {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil
+	}
+
+	var digests []string
+	for _, rd := range repoDigests {
+		parsed, err := reference.ParseNormalizedNamed(rd)
+		if err != nil {
+			continue
+		}
+		if canonical, ok := parsed.(reference.Canonical); ok && parsed.Name() == named.Name() {
+			digests = append(digests, canonical.Digest().String())
+		}
+	}
+	return digests
+}
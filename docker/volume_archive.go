@@ -0,0 +1,379 @@
+package docker
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// VolumeHelperLabel marks the temporary containers used to access volume contents.
+// Its value is the name of the volume.
+const VolumeHelperLabel = "dsp.volume-helper"
+
+// isVolumeHelper reports whether a container is a helper container of this application.
+// Helpers are internal and left out of container listings.
+func isVolumeHelper(container types.Container) bool {
+	_, ok := container.Labels[VolumeHelperLabel]
+	return ok
+}
+
+// volumeMountPath is where helper containers mount the volume
+const volumeMountPath = "/volume"
+
+var (
+	// ErrVolumeNotEmpty is returned when restoring into a volume that has contents and was not cleared
+	ErrVolumeNotEmpty = errors.New("volume is not empty")
+	// ErrVolumeInUse is returned when restoring into a volume mounted by running containers
+	ErrVolumeInUse = errors.New("volume is in use by running containers")
+	// ErrVolumeBusy is returned when exploring a volume that is being removed or pruned
+	ErrVolumeBusy = errors.New("volume is being removed")
+	// ErrInvalidArchive is returned for uploaded archives that cannot be read or extracted
+	ErrInvalidArchive = errors.New("invalid archive")
+)
+
+// RestoreVolumeOptions holds the settings of a volume restore
+type RestoreVolumeOptions struct {
+	Clear              bool // Delete the current contents of the volume first
+	StopContainers     bool // Stop running containers using the volume and start them again afterwards
+	DefaultStopTimeout int  // Stop timeout for containers without their own, in seconds
+}
+
+// createVolumeHelper creates (but does not start) a helper container with the volume mounted.
+// The archive API works on created containers, so most operations never run a process.
+func (c *Client) createVolumeHelper(ctx context.Context, volumeName, explorerImage string, readOnly bool, cmd []string) (string, error) {
+	containerName := fmt.Sprintf("volume-helper-%s-%d", volumeName, time.Now().UnixNano())
+
+	bind := volumeName + ":" + volumeMountPath
+	if readOnly {
+		bind += ":ro"
+	}
+
+	config := &container.Config{
+		Image:  explorerImage,
+		Cmd:    cmd,
+		Labels: map[string]string{VolumeHelperLabel: volumeName},
+	}
+	hostConfig := &container.HostConfig{
+		Binds: []string{bind},
+	}
+
+	resp, err := c.cli.ContainerCreate(ctx, config, hostConfig, nil, nil, containerName)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary container: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// removeVolumeHelper removes a helper container, even if the request was cancelled
+func (c *Client) removeVolumeHelper(containerID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := c.cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true}); err != nil {
+		log.Printf("Warning: failed to remove volume helper container %s: %v", containerID[:shortIDLength], err)
+	}
+}
+
+// runVolumeHelper runs a command in a helper container with the volume mounted read-write
+// and fails if it exits with a non-zero status
+func (c *Client) runVolumeHelper(ctx context.Context, volumeName, explorerImage string, cmd []string) error {
+	id, err := c.createVolumeHelper(ctx, volumeName, explorerImage, false, cmd)
+	if err != nil {
+		return err
+	}
+	defer c.removeVolumeHelper(id)
+
+	if err := c.cli.ContainerStart(ctx, id, types.ContainerStartOptions{}); err != nil {
+		return fmt.Errorf("failed to start temporary container: %w", err)
+	}
+
+	var exitCode int64
+	statusCh, errCh := c.cli.ContainerWait(ctx, id, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("error waiting for container: %w", err)
+		}
+	case status := <-statusCh:
+		exitCode = status.StatusCode
+	}
+	if exitCode == 0 {
+		return nil
+	}
+
+	output := ""
+	logReader, err := c.cli.ContainerLogs(ctx, id, types.ContainerLogsOptions{ShowStderr: true})
+	if err == nil {
+		var stderr strings.Builder
+		stdcopy.StdCopy(io.Discard, &stderr, logReader)
+		logReader.Close()
+		output = strings.TrimSpace(stderr.String())
+	}
+	return fmt.Errorf("command %q exited with status %d: %s", strings.Join(cmd, " "), exitCode, output)
+}
+
+// BackupVolume streams the contents of a volume as a gzip-compressed tar archive. Entries are
+// relative to the volume root, like `tar -C <volume> -czf - .`. The helper container is removed
+// once the returned reader has been read to the end or closed.
+func (c *Client) BackupVolume(ctx context.Context, volumeName, explorerImage string) (io.ReadCloser, error) {
+	// Creating a container would silently create a missing volume
+	if _, err := c.cli.VolumeInspect(ctx, volumeName); err != nil {
+		return nil, err
+	}
+
+	id, err := c.createVolumeHelper(ctx, volumeName, explorerImage, true, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	content, _, err := c.cli.CopyFromContainer(ctx, id, volumeMountPath)
+	if err != nil {
+		c.removeVolumeHelper(id)
+		return nil, fmt.Errorf("failed to read volume: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer c.removeVolumeHelper(id)
+		defer content.Close()
+		pw.CloseWithError(rebaseVolumeArchive(content, pw))
+	}()
+	return pr, nil
+}
+
+// rebaseVolumeArchive rewrites the archive API output, whose entries are prefixed with the
+// mount directory name, into a gzip-compressed tar relative to the volume root
+func rebaseVolumeArchive(src io.Reader, dst io.Writer) error {
+	prefix := strings.TrimPrefix(volumeMountPath, "/") + "/"
+
+	gz := gzip.NewWriter(dst)
+	tr := tar.NewReader(src)
+	tw := tar.NewWriter(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimPrefix(header.Name, prefix)
+		if name == "" || name == header.Name {
+			// The volume root itself
+			continue
+		}
+		header.Name = name
+		if header.Typeflag == tar.TypeLink {
+			header.Linkname = strings.TrimPrefix(header.Linkname, prefix)
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// volumeIsEmpty reports whether the volume mounted in a helper container has no entries
+func (c *Client) volumeIsEmpty(ctx context.Context, helperID string) (bool, error) {
+	content, _, err := c.cli.CopyFromContainer(ctx, helperID, volumeMountPath)
+	if err != nil {
+		return false, err
+	}
+	defer content.Close()
+
+	// The first entry is the volume root; any further entry means the volume has contents
+	tr := tar.NewReader(content)
+	if _, err := tr.Next(); err != nil {
+		return false, err
+	}
+	_, err = tr.Next()
+	if err == io.EOF {
+		return true, nil
+	}
+	return false, err
+}
+
+// runningVolumeContainers lists the IDs of running containers that mount the volume
+func (c *Client) runningVolumeContainers(ctx context.Context, volumeName string) ([]string, error) {
+	containers, err := c.cli.ContainerList(ctx, types.ContainerListOptions{
+		Filters: filters.NewArgs(filters.Arg("volume", volumeName)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, container := range containers {
+		// The explorer helper only reads the volume and is not stopped
+		if isVolumeHelper(container) {
+			continue
+		}
+		ids = append(ids, container.ID[:shortIDLength])
+	}
+	return ids, nil
+}
+
+// RestoreVolume extracts a tar archive (optionally gzip or bzip2-compressed) into a volume. The
+// archive is read completely first, so that a truncated or corrupt upload is rejected before
+// anything is stopped or cleared. The volume must be empty unless opts.Clear is set. Running
+// containers using the volume are only stopped with opts.StopContainers; they are started again
+// afterwards, also when the restore fails.
+func (c *Client) RestoreVolume(ctx context.Context, volumeName, explorerImage string, archive io.ReadSeeker, opts RestoreVolumeOptions) (*models.VolumeRestoreResult, error) {
+	// Creating a container would silently create a missing volume
+	if _, err := c.cli.VolumeInspect(ctx, volumeName); err != nil {
+		return nil, err
+	}
+
+	if err := checkArchive(archive); err != nil {
+		return nil, err
+	}
+
+	result := &models.VolumeRestoreResult{
+		Volume:            volumeName,
+		StoppedContainers: []string{},
+	}
+
+	running, err := c.runningVolumeContainers(ctx, volumeName)
+	if err != nil {
+		return nil, err
+	}
+	if len(running) > 0 && !opts.StopContainers {
+		return nil, fmt.Errorf("%w: %s", ErrVolumeInUse, strings.Join(running, ", "))
+	}
+
+	for _, containerID := range running {
+		timeout := c.ResolveStopTimeout(ctx, containerID, nil, opts.DefaultStopTimeout)
+		if err := c.StopContainer(ctx, containerID, timeout); err != nil {
+			err = fmt.Errorf("failed to stop container %s: %w", containerID, err)
+			return result, c.startStoppedContainers(ctx, result.StoppedContainers, err)
+		}
+		result.StoppedContainers = append(result.StoppedContainers, containerID)
+	}
+
+	err = c.restoreVolumeContents(ctx, volumeName, explorerImage, archive, opts.Clear, result)
+	return result, c.startStoppedContainers(ctx, result.StoppedContainers, err)
+}
+
+// checkArchive reads a tar archive to the end to make sure it can be extracted, then rewinds it
+func checkArchive(archive io.ReadSeeker) error {
+	entries := 0
+	err := readArchive(archive, func(*tar.Header, io.Reader) error {
+		entries++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if entries == 0 {
+		return fmt.Errorf("%w: archive is empty", ErrInvalidArchive)
+	}
+	_, err = archive.Seek(0, io.SeekStart)
+	return err
+}
+
+// readArchive calls fn for each entry of a tar archive, which may be gzip or bzip2-compressed
+func readArchive(archive io.Reader, fn func(*tar.Header, io.Reader) error) error {
+	br := bufio.NewReader(archive)
+	magic, _ := br.Peek(3)
+
+	var src io.Reader = br
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		defer gz.Close()
+		src = gz
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		src = bzip2.NewReader(br)
+	case bytes.HasPrefix(magic, []byte{0xfd, '7', 'z'}):
+		return fmt.Errorf("%w: xz compression is not supported", ErrInvalidArchive)
+	}
+
+	tr := tar.NewReader(src)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		if err := fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// restoreVolumeContents clears the volume if requested and extracts the archive into it
+func (c *Client) restoreVolumeContents(ctx context.Context, volumeName, explorerImage string, archive io.Reader, clear bool, result *models.VolumeRestoreResult) error {
+	if clear {
+		if err := c.runVolumeHelper(ctx, volumeName, explorerImage, []string{"find", volumeMountPath, "-mindepth", "1", "-delete"}); err != nil {
+			return fmt.Errorf("failed to clear volume: %w", err)
+		}
+		result.Cleared = true
+	}
+
+	id, err := c.createVolumeHelper(ctx, volumeName, explorerImage, false, nil)
+	if err != nil {
+		return err
+	}
+	defer c.removeVolumeHelper(id)
+
+	if !clear {
+		empty, err := c.volumeIsEmpty(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to read volume: %w", err)
+		}
+		if !empty {
+			return ErrVolumeNotEmpty
+		}
+	}
+
+	// The daemon decompresses gzip and bzip2 archives itself
+	if err := c.cli.CopyToContainer(ctx, id, volumeMountPath, archive, types.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to extract archive: %w", err)
+	}
+	return nil
+}
+
+// startStoppedContainers starts the containers stopped for a restore. Failures are added to
+// the restore error so they are not lost when the restore itself failed.
+func (c *Client) startStoppedContainers(ctx context.Context, containerIDs []string, restoreErr error) error {
+	// Start the containers again even if the request was cancelled
+	ctx = context.WithoutCancel(ctx)
+
+	errs := []error{restoreErr}
+	for _, containerID := range containerIDs {
+		if err := c.StartContainer(ctx, containerID); err != nil {
+			log.Printf("Failed to start container %s after volume restore: %v", containerID, err)
+			errs = append(errs, fmt.Errorf("failed to start container %s: %w", containerID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,223 @@
+package docker
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// ArchiveFormatTar streams directories as uncompressed tar archives
+	ArchiveFormatTar = "tar"
+	// ArchiveFormatZip streams directories as zip archives
+	ArchiveFormatZip = "zip"
+)
+
+var (
+	// ErrNotRegularFile is returned when downloading a path that is not a regular file
+	ErrNotRegularFile = errors.New("not a regular file")
+	// ErrNotDirectory is returned when archiving a path that is not a directory
+	ErrNotDirectory = errors.New("not a directory")
+)
+
+// FileStream is a file opened for download from a container or volume. It must be closed.
+type FileStream struct {
+	io.Reader
+	Name    string
+	Size    int64
+	ModTime time.Time
+
+	content io.Closer
+	release func()
+}
+
+// Close releases the archive stream and the helper container
+func (f *FileStream) Close() error {
+	err := f.content.Close()
+	if f.release != nil {
+		f.release()
+	}
+	return err
+}
+
+// openVolumeFile opens a regular file of the volume mounted by a helper container for streaming.
+// The content comes from the archive API, so files of any size and content are returned
+// unchanged. release is called once the helper is no longer needed, including on failure.
+func (c *Client) openVolumeFile(ctx context.Context, helperID, filePath string, release func()) (*FileStream, error) {
+	tr, header, stat, content, err := c.copyVolumeFile(ctx, helperID, filePath)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// The tar reader is positioned at the file content and ends with it
+	return &FileStream{
+		Reader:  tr,
+		Name:    path.Base(filePath),
+		Size:    header.Size,
+		ModTime: stat.Mtime,
+		content: content,
+		release: release,
+	}, nil
+}
+
+// archiveVolumeDirectory streams a directory of the volume mounted by a helper container as a tar
+// or zip archive. Entries are placed under the directory name, or under the volume name for the
+// volume root. release is called once the archive has been read to the end or closed, or on failure.
+func (c *Client) archiveVolumeDirectory(ctx context.Context, helperID, volumeName, dirPath, format string, release func()) (io.ReadCloser, error) {
+	if format != ArchiveFormatTar && format != ArchiveFormatZip {
+		release()
+		return nil, fmt.Errorf("unsupported archive format %q", format)
+	}
+
+	stat, err := c.statVolumeFile(ctx, helperID, dirPath)
+	if err == nil && !stat.Mode.IsDir() {
+		err = fmt.Errorf("%w: %s", ErrNotDirectory, dirPath)
+	}
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	content, _, err := c.cli.CopyFromContainer(ctx, helperID, volumeFilePath(dirPath))
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("failed to read volume: %w", err)
+	}
+
+	// The archive API names entries after the last element of the copied path
+	rootName := path.Base(dirPath)
+	if dirPath == "/" {
+		rootName = volumeName
+	}
+	copiedName := path.Base(volumeFilePath(dirPath))
+	rename := archiveRenamer(copiedName, rootName)
+
+	// Hard links are stored as copies in zip archives, read again from the volume
+	copyLink := func(linkname string, dst io.Writer) error {
+		rest, ok := strings.CutPrefix(linkname, copiedName+"/")
+		if !ok {
+			return fmt.Errorf("hard link target %q is outside the archive", linkname)
+		}
+		tr, _, _, linked, err := c.copyVolumeFile(ctx, helperID, path.Join(dirPath, rest))
+		if err != nil {
+			return fmt.Errorf("failed to read hard link target %q: %w", linkname, err)
+		}
+		defer linked.Close()
+		_, err = io.Copy(dst, tr)
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer release()
+		defer content.Close()
+		if format == ArchiveFormatZip {
+			pw.CloseWithError(tarToZip(tar.NewReader(content), pw, rename, copyLink))
+		} else {
+			pw.CloseWithError(renameTarEntries(tar.NewReader(content), pw, rename))
+		}
+	}()
+	return pr, nil
+}
+
+// archiveRenamer returns a function replacing the top-level directory of entry names
+func archiveRenamer(from, to string) func(string) string {
+	return func(name string) string {
+		if name == from {
+			return to
+		}
+		if rest, ok := strings.CutPrefix(name, from+"/"); ok {
+			return to + "/" + rest
+		}
+		return name
+	}
+}
+
+// renameTarEntries copies a tar archive, renaming its entries
+func renameTarEntries(tr *tar.Reader, dst io.Writer, rename func(string) string) error {
+	tw := tar.NewWriter(dst)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		header.Name = rename(strings.TrimSuffix(header.Name, "/"))
+		if header.Typeflag == tar.TypeDir {
+			header.Name += "/"
+		}
+		if header.Typeflag == tar.TypeLink {
+			header.Linkname = rename(header.Linkname)
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// tarToZip converts a tar archive into a zip archive, renaming its entries. Zip has no
+// equivalent for hard links, so they are stored as copies of the linked file written by
+// copyLink. Devices and FIFOs cannot be represented at all and are left out.
+func tarToZip(tr *tar.Reader, dst io.Writer, rename func(string) string, copyLink func(linkname string, dst io.Writer) error) error {
+	zw := zip.NewWriter(dst)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg, tar.TypeLink, tar.TypeDir, tar.TypeSymlink:
+		default:
+			continue
+		}
+
+		fh, err := zip.FileInfoHeader(header.FileInfo())
+		if err != nil {
+			return err
+		}
+		fh.Name = rename(strings.TrimSuffix(header.Name, "/"))
+		fh.Modified = header.ModTime
+		fh.Method = zip.Deflate
+		if header.Typeflag == tar.TypeDir {
+			fh.Name += "/"
+			fh.Method = zip.Store
+		}
+
+		w, err := zw.CreateHeader(fh)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeReg:
+			_, err = io.Copy(w, tr)
+		case tar.TypeLink:
+			err = copyLink(header.Linkname, w)
+		case tar.TypeSymlink:
+			// Zip stores the target of a symbolic link as its content
+			_, err = io.WriteString(w, header.Linkname)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
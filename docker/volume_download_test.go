@@ -0,0 +1,84 @@
+package docker
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTarToZip(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	entries := []struct {
+		header  tar.Header
+		content string
+	}{
+		{tar.Header{Typeflag: tar.TypeDir, Name: "_data/", Mode: 0o755}, ""},
+		{tar.Header{Typeflag: tar.TypeReg, Name: "_data/file.txt", Mode: 0o644, Size: 5}, "hello"},
+		{tar.Header{Typeflag: tar.TypeLink, Name: "_data/link.txt", Linkname: "_data/file.txt", Mode: 0o644}, ""},
+		{tar.Header{Typeflag: tar.TypeSymlink, Name: "_data/symlink", Linkname: "file.txt", Mode: 0o777}, ""},
+		{tar.Header{Typeflag: tar.TypeFifo, Name: "_data/fifo", Mode: 0o644}, ""},
+	}
+	for _, entry := range entries {
+		if err := tw.WriteHeader(&entry.header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(tw, entry.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var links []string
+	copyLink := func(linkname string, dst io.Writer) error {
+		links = append(links, linkname)
+		_, err := io.WriteString(dst, "hello")
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := tarToZip(tar.NewReader(&buf), &out, archiveRenamer("_data", "data"), copyLink); err != nil {
+		t.Fatalf("tarToZip() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip archive: %v", err)
+	}
+	got := make(map[string]string)
+	for _, file := range zr.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[file.Name] = string(data)
+	}
+
+	want := map[string]string{
+		"data/":         "",
+		"data/file.txt": "hello",
+		"data/link.txt": "hello",
+		"data/symlink":  "file.txt",
+	}
+	if len(got) != len(want) {
+		t.Errorf("zip entries = %v, want %v", got, want)
+	}
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("zip entry %q = %q, want %q", name, got[name], content)
+		}
+	}
+	if strings.Join(links, ",") != "_data/file.txt" {
+		t.Errorf("copied links = %v, want [_data/file.txt]", links)
+	}
+}
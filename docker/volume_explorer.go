@@ -0,0 +1,483 @@
+package docker
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+const (
+	// explorerIdleTimeout is how long an unused explorer helper keeps running
+	explorerIdleTimeout = 5 * time.Minute
+	// explorerReapInterval is how often idle explorer helpers are looked for
+	explorerReapInterval = time.Minute
+)
+
+// explorerHelper is the long-lived, read-only helper container exploring one volume
+type explorerHelper struct {
+	client *Client
+	image  string
+	ready  chan struct{} // Closed once the container has been started, or failed to start
+
+	// Set before ready is closed
+	id  string
+	err error
+
+	// Guarded by volumeExplorer.mu
+	users    int
+	lastUsed time.Time
+	retired  bool // No longer handed out, removed once unused
+	removed  bool
+}
+
+// volumeExplorer keeps one running read-only helper container per explored volume, so that
+// browsing uses the exec and archive APIs instead of creating a container for each request.
+// Helpers are removed after explorerIdleTimeout without use.
+type volumeExplorer struct {
+	mu        sync.Mutex
+	helpers   map[string]*explorerHelper // Volume name -> helper
+	suspended map[string]int             // Volume name, or "" for all volumes -> suspensions in progress
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// newVolumeExplorer creates a volume explorer and starts reaping idle helpers
+func newVolumeExplorer() *volumeExplorer {
+	e := &volumeExplorer{
+		helpers:   make(map[string]*explorerHelper),
+		suspended: make(map[string]int),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go e.reapIdle()
+	return e
+}
+
+// acquire returns the ID of the running helper of a volume, starting one if needed.
+// The returned function must be called once the helper is no longer used.
+func (e *volumeExplorer) acquire(ctx context.Context, c *Client, volumeName, explorerImage string) (string, func(), error) {
+	// A second attempt replaces a helper that was stopped or removed behind our back
+	for attempt := 0; attempt < 2; attempt++ {
+		e.mu.Lock()
+		if e.suspended[volumeName] > 0 || e.suspended[""] > 0 {
+			e.mu.Unlock()
+			return "", nil, fmt.Errorf("%w: %s", ErrVolumeBusy, volumeName)
+		}
+		h := e.helpers[volumeName]
+		var stale *explorerHelper
+		if h != nil && (h.client != c || h.image != explorerImage) {
+			// The socket or the image changed since the helper was started
+			if e.retireLocked(volumeName, h) {
+				stale = h
+			}
+			h = nil
+		}
+		created := h == nil
+		if created {
+			h = &explorerHelper{client: c, image: explorerImage, ready: make(chan struct{})}
+			e.helpers[volumeName] = h
+		}
+		h.users++
+		e.mu.Unlock()
+		e.remove(stale)
+
+		var once sync.Once
+		release := func() {
+			once.Do(func() { e.release(volumeName, h) })
+		}
+
+		if created {
+			h.id, h.err = c.startExplorerHelper(ctx, volumeName, explorerImage)
+			if h.err != nil {
+				e.mu.Lock()
+				if e.helpers[volumeName] == h {
+					delete(e.helpers, volumeName)
+				}
+				e.mu.Unlock()
+			}
+			close(h.ready)
+		} else {
+			select {
+			case <-h.ready:
+			case <-ctx.Done():
+				release()
+				return "", nil, ctx.Err()
+			}
+		}
+
+		if h.err != nil {
+			release()
+			return "", nil, h.err
+		}
+		if created || c.helperRunning(ctx, h.id) {
+			return h.id, release, nil
+		}
+
+		e.mu.Lock()
+		e.retireLocked(volumeName, h)
+		e.mu.Unlock()
+		release()
+	}
+	return "", nil, errors.New("volume explorer helper container is not running")
+}
+
+// release marks a helper as unused, removing it if it was retired in the meantime
+func (e *volumeExplorer) release(volumeName string, h *explorerHelper) {
+	e.mu.Lock()
+	h.users--
+	h.lastUsed = time.Now()
+	unused := h.retired && h.users == 0
+	e.mu.Unlock()
+
+	if unused {
+		e.remove(h)
+	}
+}
+
+// retireLocked stops handing out a helper and reports whether it can be removed right away.
+// e.mu must be held.
+func (e *volumeExplorer) retireLocked(volumeName string, h *explorerHelper) bool {
+	if e.helpers[volumeName] == h {
+		delete(e.helpers, volumeName)
+	}
+	h.retired = true
+	// The creator holds a use until the helper is ready
+	return h.users == 0
+}
+
+// remove removes the container of a helper once
+func (e *volumeExplorer) remove(h *explorerHelper) {
+	if h == nil {
+		return
+	}
+	e.mu.Lock()
+	skip := h.removed || h.id == ""
+	h.removed = true
+	e.mu.Unlock()
+
+	if !skip {
+		h.client.removeVolumeHelper(h.id)
+	}
+}
+
+// Release removes the helper of a volume, even if it is in use, so that the volume can be
+// removed. Downloads still reading from it fail.
+func (e *volumeExplorer) Release(volumeName string) {
+	e.mu.Lock()
+	h := e.helpers[volumeName]
+	if h != nil {
+		e.retireLocked(volumeName, h)
+	}
+	e.mu.Unlock()
+
+	if h != nil {
+		select {
+		case <-h.ready:
+			e.remove(h)
+		default:
+			// Still starting, removed when its creator releases it
+		}
+	}
+}
+
+// Suspend removes the helper of a volume, even if it is in use, and refuses to start a new one
+// until the returned function is called, so that the volume can be removed. An empty volume
+// name suspends all volumes. Downloads still reading from a removed helper fail.
+func (e *volumeExplorer) Suspend(volumeName string) func() {
+	e.mu.Lock()
+	e.suspended[volumeName]++
+	helpers := []*explorerHelper{}
+	for name, h := range e.helpers {
+		if volumeName == "" || name == volumeName {
+			e.retireLocked(name, h)
+			helpers = append(helpers, h)
+		}
+	}
+	e.mu.Unlock()
+
+	for _, h := range helpers {
+		// A helper that is still starting would keep the volume in use as well
+		<-h.ready
+		e.remove(h)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			if e.suspended[volumeName]--; e.suspended[volumeName] == 0 {
+				delete(e.suspended, volumeName)
+			}
+		})
+	}
+}
+
+// ReleaseAll removes all helpers, e.g. before the Docker client they belong to is closed
+func (e *volumeExplorer) ReleaseAll() {
+	e.mu.Lock()
+	names := make([]string, 0, len(e.helpers))
+	for name := range e.helpers {
+		names = append(names, name)
+	}
+	e.mu.Unlock()
+
+	for _, name := range names {
+		e.Release(name)
+	}
+}
+
+// reapIdle periodically removes helpers that have not been used for explorerIdleTimeout
+func (e *volumeExplorer) reapIdle() {
+	defer close(e.done)
+	ticker := time.NewTicker(explorerReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+		}
+
+		idle := []*explorerHelper{}
+		e.mu.Lock()
+		for name, h := range e.helpers {
+			if h.users == 0 && time.Since(h.lastUsed) > explorerIdleTimeout {
+				e.retireLocked(name, h)
+				idle = append(idle, h)
+			}
+		}
+		e.mu.Unlock()
+
+		for _, h := range idle {
+			e.remove(h)
+		}
+	}
+}
+
+// Close stops reaping and removes all helpers
+func (e *volumeExplorer) Close() {
+	close(e.stop)
+	<-e.done
+	e.ReleaseAll()
+}
+
+// startExplorerHelper creates and starts a read-only helper container for an existing volume.
+// It only sleeps; the explorer works through exec instances and the archive API.
+func (c *Client) startExplorerHelper(ctx context.Context, volumeName, explorerImage string) (string, error) {
+	// Creating a container would silently create a missing volume
+	if _, err := c.cli.VolumeInspect(ctx, volumeName); err != nil {
+		return "", err
+	}
+
+	id, err := c.createVolumeHelper(ctx, volumeName, explorerImage, true, []string{"sleep", "infinity"})
+	if err != nil {
+		return "", err
+	}
+	if err := c.cli.ContainerStart(ctx, id, types.ContainerStartOptions{}); err != nil {
+		c.removeVolumeHelper(id)
+		return "", fmt.Errorf("failed to start explorer container: %w", err)
+	}
+	return id, nil
+}
+
+// helperRunning reports whether a helper container is still running
+func (c *Client) helperRunning(ctx context.Context, helperID string) bool {
+	inspect, err := c.cli.ContainerInspect(ctx, helperID)
+	return err == nil && inspect.State != nil && inspect.State.Running
+}
+
+// removeVolumeHelpers removes all helper containers, left behind by a previous process
+func (c *Client) removeVolumeHelpers(ctx context.Context) error {
+	containers, err := c.cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", VolumeHelperLabel)),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, container := range containers {
+		if err := c.cli.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			log.Printf("Warning: failed to remove volume helper container %s: %v", container.ID[:shortIDLength], err)
+		}
+	}
+	if len(containers) > 0 {
+		log.Printf("Removed %d leftover volume helper container(s)", len(containers))
+	}
+	return nil
+}
+
+// execVolumeHelper runs a command in a running helper container and returns its standard output.
+// It fails if the command exits with a non-zero status.
+func (c *Client) execVolumeHelper(ctx context.Context, helperID string, cmd []string) (string, error) {
+	exec, err := c.cli.ContainerExecCreate(ctx, helperID, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create exec instance: %w", err)
+	}
+
+	resp, err := c.cli.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return "", fmt.Errorf("failed to attach to exec instance: %w", err)
+	}
+	defer resp.Close()
+
+	var stdout, stderr strings.Builder
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, resp.Reader); err != nil {
+		return "", fmt.Errorf("failed to read command output: %w", err)
+	}
+
+	exitCode, err := c.execExitCode(ctx, exec.ID)
+	if err != nil {
+		return "", err
+	}
+	if exitCode != 0 {
+		return "", fmt.Errorf("command failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// execExitCode returns the exit code of an exec instance whose output has ended
+func (c *Client) execExitCode(ctx context.Context, execID string) (int, error) {
+	// The exit code can lag shortly behind the end of the output
+	for i := 0; ; i++ {
+		inspect, err := c.cli.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return 0, err
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+		if i == 50 {
+			return 0, errors.New("command did not exit")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// listVolumeDirectory lists a directory of the volume mounted by a running helper container
+func (c *Client) listVolumeDirectory(ctx context.Context, helperID, dirPath string) ([]models.VolumeFileInfo, error) {
+	output, err := c.execVolumeHelper(ctx, helperID, []string{"ls", "-la", "--full-time", volumeMountPath + dirPath})
+	if err != nil {
+		return nil, err
+	}
+	return parseVolumeListing(dirPath, output)
+}
+
+// parseVolumeListing parses the output of `ls -la --full-time` for a directory
+func parseVolumeListing(dirPath, output string) ([]models.VolumeFileInfo, error) {
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	files := []models.VolumeFileInfo{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+
+	// Skip the first line (total)
+	firstLine := true
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if firstLine {
+			firstLine = false
+			if strings.HasPrefix(line, "total") {
+				continue
+			}
+		}
+
+		// Parse ls -la output
+		// Expected format: mode links owner group size date time timezone filename
+		// Example: -rw-r--r-- 1 root root 12 2025-12-07 14:51:49.123456789 +0000 test.txt
+		const minFieldCount = 9
+		fields := strings.Fields(line)
+		if len(fields) < minFieldCount {
+			continue
+		}
+
+		mode := fields[0]
+		sizeStr := fields[4]
+		dateTime := strings.Join(fields[5:8], " ")
+		name := strings.Join(fields[8:], " ")
+
+		// Skip . and ..
+		if name == "." || name == ".." {
+			continue
+		}
+		// Symbolic links are listed as "name -> target"
+		if strings.HasPrefix(mode, "l") {
+			name, _, _ = strings.Cut(name, " -> ")
+		}
+
+		isDir := strings.HasPrefix(mode, "d")
+		size := int64(0)
+		if !isDir {
+			if s, err := strconv.ParseInt(sizeStr, 10, 64); err == nil {
+				size = s
+			}
+		}
+
+		files = append(files, models.VolumeFileInfo{
+			Name:        name,
+			Path:        path.Join(dirPath, name),
+			IsDirectory: isDir,
+			Size:        size,
+			Mode:        mode,
+			ModTime:     dateTime,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading directory listing: %w", err)
+	}
+
+	return files, nil
+}
+
+// copyVolumeFile opens a regular file of the volume mounted by a helper container through the
+// archive API. A symbolic link to a file inside the volume is followed.
+func (c *Client) copyVolumeFile(ctx context.Context, helperID, filePath string) (*tar.Reader, *tar.Header, types.ContainerPathStat, io.Closer, error) {
+	tr, header, stat, content, err := c.copyRegularFile(ctx, helperID, volumeFilePath(filePath), volumeMountPath)
+	if errors.Is(err, ErrNotRegularFile) {
+		err = fmt.Errorf("%w: %s", ErrNotRegularFile, filePath)
+	}
+	return tr, header, stat, content, err
+}
+
+// readVolumeFile reads a file of the volume mounted by a helper container
+func (c *Client) readVolumeFile(ctx context.Context, helperID, filePath string) (*models.VolumeFileContent, error) {
+	tr, _, stat, content, err := c.copyVolumeFile(ctx, helperID, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(tr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file content: %w", err)
+	}
+
+	return &models.VolumeFileContent{
+		Path:    filePath,
+		Content: string(data),
+		Size:    int64(len(data)),
+		// The modification time lets writes detect concurrent changes
+		ModTime: stat.Mtime.Format(FileTimeFormat),
+	}, nil
+}
@@ -0,0 +1,404 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// FileTimeFormat is the modification time format of volume file listings (ls --full-time)
+const FileTimeFormat = "2006-01-02 15:04:05.000000000 -0700"
+
+var (
+	// ErrFileExists is returned when a write would replace an existing file without permission
+	ErrFileExists = errors.New("file already exists")
+	// ErrFileChanged is returned when a file no longer matches the version it was read at
+	ErrFileChanged = errors.New("file was modified since it was read")
+)
+
+// FileVersion identifies the state of a file as it was read, for optimistic concurrency checks
+type FileVersion struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// fileModeString formats a file mode the way ls -l does, so that listings read through the
+// archive API match the ones read with ls
+func fileModeString(mode os.FileMode) string {
+	b := []byte("----------")
+	switch {
+	case mode.IsDir():
+		b[0] = 'd'
+	case mode&os.ModeSymlink != 0:
+		b[0] = 'l'
+	case mode&os.ModeNamedPipe != 0:
+		b[0] = 'p'
+	case mode&os.ModeSocket != 0:
+		b[0] = 's'
+	case mode&os.ModeCharDevice != 0:
+		b[0] = 'c'
+	case mode&os.ModeDevice != 0:
+		b[0] = 'b'
+	}
+	const rwx = "rwxrwxrwx"
+	for i := 0; i < 9; i++ {
+		if mode&(1<<uint(8-i)) != 0 {
+			b[i+1] = rwx[i]
+		}
+	}
+	special := func(i int, set bool, c byte) {
+		if !set {
+			return
+		}
+		if b[i] == 'x' {
+			b[i] = c
+		} else {
+			b[i] = c - 'a' + 'A'
+		}
+	}
+	special(3, mode&os.ModeSetuid != 0, 's')
+	special(6, mode&os.ModeSetgid != 0, 's')
+	special(9, mode&os.ModeSticky != 0, 't')
+	return string(b)
+}
+
+// ParseFileTime parses a modification time in FileTimeFormat or RFC 3339
+func ParseFileTime(value string) (time.Time, error) {
+	if t, err := time.Parse(FileTimeFormat, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// matches compares the version with the current state of a file. Times without a fractional
+// part are compared to the second.
+func (v *FileVersion) matches(stat types.ContainerPathStat) bool {
+	if stat.Size != v.Size && !stat.Mode.IsDir() {
+		return false
+	}
+	if v.ModTime.Nanosecond() == 0 {
+		return stat.Mtime.Truncate(time.Second).Equal(v.ModTime)
+	}
+	return stat.Mtime.Equal(v.ModTime)
+}
+
+// FileUpload is a file to be written into a directory of a volume or container
+type FileUpload struct {
+	Name    string // File name without directories
+	Size    int64
+	Content io.Reader
+}
+
+// volumeFilePath maps a path inside the volume to the helper container's mount
+func volumeFilePath(p string) string {
+	return path.Join(volumeMountPath, p)
+}
+
+// openVolumeHelper creates a read-write helper container for an existing volume
+func (c *Client) openVolumeHelper(ctx context.Context, volumeName, explorerImage string) (string, error) {
+	// Creating a container would silently create a missing volume
+	if _, err := c.cli.VolumeInspect(ctx, volumeName); err != nil {
+		return "", err
+	}
+	return c.createVolumeHelper(ctx, volumeName, explorerImage, false, nil)
+}
+
+// statVolumeFile returns the state of a path in the volume mounted by a helper container
+func (c *Client) statVolumeFile(ctx context.Context, helperID, p string) (types.ContainerPathStat, error) {
+	return c.cli.ContainerStatPath(ctx, helperID, volumeFilePath(p))
+}
+
+// volumeFileHeader returns the tar header of a path, which unlike a stat carries its owner.
+// Only the first entry is read, so directories are not archived as a whole.
+func (c *Client) volumeFileHeader(ctx context.Context, helperID, p string) (*tar.Header, error) {
+	return c.pathHeader(ctx, helperID, volumeFilePath(p))
+}
+
+// checkFileVersion verifies that a path still matches the expected version. Without an expected
+// version, an existing file is only accepted with overwrite. It returns the current header of
+// the file, or nil if it does not exist.
+func (c *Client) checkFileVersion(ctx context.Context, helperID, p string, expected *FileVersion, overwrite bool) (*tar.Header, error) {
+	stat, err := c.statVolumeFile(ctx, helperID, p)
+	if client.IsErrNotFound(err) {
+		if expected != nil {
+			return nil, fmt.Errorf("%w: %s no longer exists", ErrFileChanged, p)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if expected != nil {
+		if !expected.matches(stat) {
+			return nil, fmt.Errorf("%w: %s", ErrFileChanged, p)
+		}
+	} else if !overwrite {
+		return nil, fmt.Errorf("%w: %s", ErrFileExists, p)
+	}
+	if stat.Mode.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", p)
+	}
+	if stat.Mode&os.ModeSymlink != 0 {
+		return nil, fmt.Errorf("%s is a symbolic link", p)
+	}
+
+	return c.volumeFileHeader(ctx, helperID, p)
+}
+
+// newFileHeader builds the tar header of a written file. Replaced files keep their owner and
+// permissions; new files take the owner of their directory.
+func newFileHeader(name string, size int64, current, parent *tar.Header) *tar.Header {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0644,
+		ModTime:  time.Now(),
+	}
+	owner := parent
+	if current != nil {
+		owner = current
+		header.Mode = current.Mode
+	}
+	if owner != nil {
+		header.Uid, header.Gid = owner.Uid, owner.Gid
+		header.Uname, header.Gname = owner.Uname, owner.Gname
+	}
+	return header
+}
+
+// volumeFileInfo stats a path and converts it into the listing model
+func (c *Client) volumeFileInfo(ctx context.Context, helperID, p string) (*models.VolumeFileInfo, error) {
+	stat, err := c.statVolumeFile(ctx, helperID, p)
+	if err != nil {
+		return nil, err
+	}
+	return &models.VolumeFileInfo{
+		Name:        path.Base(p),
+		Path:        p,
+		IsDirectory: stat.Mode.IsDir(),
+		Size:        stat.Size,
+		Mode:        fileModeString(stat.Mode),
+		ModTime:     stat.Mtime.Format(FileTimeFormat),
+	}, nil
+}
+
+// WriteVolumeFile replaces or creates a file in a volume. An existing file is only replaced if
+// it still matches expected, or without an expected version if overwrite is set.
+func (c *Client) WriteVolumeFile(ctx context.Context, volumeName, filePath string, content []byte, expected *FileVersion, overwrite bool, explorerImage string) (*models.VolumeFileInfo, error) {
+	helperID, err := c.openVolumeHelper(ctx, volumeName, explorerImage)
+	if err != nil {
+		return nil, err
+	}
+	defer c.removeVolumeHelper(helperID)
+
+	current, err := c.checkFileVersion(ctx, helperID, filePath, expected, overwrite)
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Dir(filePath)
+	parent, err := c.volumeFileHeader(ctx, helperID, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(newFileHeader(path.Base(filePath), int64(len(content)), current, parent)); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := c.cli.CopyToContainer(ctx, helperID, volumeFilePath(dir), &buf, types.CopyToContainerOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	return c.volumeFileInfo(ctx, helperID, filePath)
+}
+
+// UploadVolumeFiles writes files into a directory of a volume. Existing files are only replaced
+// with overwrite; in that case they keep their owner and permissions.
+func (c *Client) UploadVolumeFiles(ctx context.Context, volumeName, dir string, files []FileUpload, overwrite bool, explorerImage string) ([]models.VolumeFileInfo, error) {
+	helperID, err := c.openVolumeHelper(ctx, volumeName, explorerImage)
+	if err != nil {
+		return nil, err
+	}
+	defer c.removeVolumeHelper(helperID)
+
+	parent, err := c.volumeFileHeader(ctx, helperID, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	if parent.Typeflag != tar.TypeDir {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	// Check every file before writing any of them
+	headers := make([]*tar.Header, len(files))
+	for i, file := range files {
+		current, err := c.checkFileVersion(ctx, helperID, path.Join(dir, file.Name), nil, overwrite)
+		if err != nil {
+			return nil, err
+		}
+		headers[i] = newFileHeader(file.Name, file.Size, current, parent)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		for i, file := range files {
+			if err := tw.WriteHeader(headers[i]); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := io.Copy(tw, file.Content); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.CloseWithError(tw.Close())
+	}()
+
+	err = c.cli.CopyToContainer(ctx, helperID, volumeFilePath(dir), pr, types.CopyToContainerOptions{})
+	pr.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write files: %w", err)
+	}
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	written := []models.VolumeFileInfo{}
+	for _, file := range files {
+		info, err := c.volumeFileInfo(ctx, helperID, path.Join(dir, file.Name))
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, *info)
+	}
+	return written, nil
+}
+
+// CreateVolumeDirectory creates a directory in a volume. The parent directory must exist;
+// the new directory takes its owner.
+func (c *Client) CreateVolumeDirectory(ctx context.Context, volumeName, dirPath, explorerImage string) (*models.VolumeFileInfo, error) {
+	helperID, err := c.openVolumeHelper(ctx, volumeName, explorerImage)
+	if err != nil {
+		return nil, err
+	}
+	defer c.removeVolumeHelper(helperID)
+
+	if _, err := c.statVolumeFile(ctx, helperID, dirPath); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFileExists, dirPath)
+	} else if !client.IsErrNotFound(err) {
+		return nil, err
+	}
+
+	parentDir := path.Dir(dirPath)
+	parent, err := c.volumeFileHeader(ctx, helperID, parentDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", parentDir, err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	err = tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     path.Base(dirPath) + "/",
+		Mode:     0755,
+		ModTime:  time.Now(),
+		Uid:      parent.Uid,
+		Gid:      parent.Gid,
+		Uname:    parent.Uname,
+		Gname:    parent.Gname,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := c.cli.CopyToContainer(ctx, helperID, volumeFilePath(parentDir), &buf, types.CopyToContainerOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	return c.volumeFileInfo(ctx, helperID, dirPath)
+}
+
+// MoveVolumeFile renames or moves a file or directory within a volume. The destination must not
+// exist. With an expected version, the source must still match it.
+func (c *Client) MoveVolumeFile(ctx context.Context, volumeName, from, to string, expected *FileVersion, explorerImage string) (*models.VolumeFileInfo, error) {
+	helperID, err := c.openVolumeHelper(ctx, volumeName, explorerImage)
+	if err != nil {
+		return nil, err
+	}
+	defer c.removeVolumeHelper(helperID)
+
+	stat, err := c.statVolumeFile(ctx, helperID, from)
+	if err != nil {
+		return nil, err
+	}
+	if expected != nil && !expected.matches(stat) {
+		return nil, fmt.Errorf("%w: %s", ErrFileChanged, from)
+	}
+	if stat.Mode.IsDir() && strings.HasPrefix(to+"/", from+"/") {
+		return nil, fmt.Errorf("cannot move %s into itself", from)
+	}
+
+	if _, err := c.statVolumeFile(ctx, helperID, to); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFileExists, to)
+	} else if !client.IsErrNotFound(err) {
+		return nil, err
+	}
+
+	if err := c.runVolumeHelper(ctx, volumeName, explorerImage, []string{"mv", "--", volumeFilePath(from), volumeFilePath(to)}); err != nil {
+		return nil, fmt.Errorf("failed to move %s: %w", from, err)
+	}
+	return c.volumeFileInfo(ctx, helperID, to)
+}
+
+// DeleteVolumeFile deletes a file or directory in a volume. Directories must be empty unless
+// recursive is set. With an expected version, the file must still match it.
+func (c *Client) DeleteVolumeFile(ctx context.Context, volumeName, filePath string, recursive bool, expected *FileVersion, explorerImage string) error {
+	helperID, err := c.openVolumeHelper(ctx, volumeName, explorerImage)
+	if err != nil {
+		return err
+	}
+	defer c.removeVolumeHelper(helperID)
+
+	stat, err := c.statVolumeFile(ctx, helperID, filePath)
+	if err != nil {
+		return err
+	}
+	if expected != nil && !expected.matches(stat) {
+		return fmt.Errorf("%w: %s", ErrFileChanged, filePath)
+	}
+
+	cmd := []string{"rm", "-f", "--", volumeFilePath(filePath)}
+	if stat.Mode.IsDir() {
+		cmd = []string{"rmdir", "--", volumeFilePath(filePath)}
+		if recursive {
+			cmd = []string{"rm", "-rf", "--", volumeFilePath(filePath)}
+		}
+	}
+
+	if err := c.runVolumeHelper(ctx, volumeName, explorerImage, cmd); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", filePath, err)
+	}
+	return nil
+}
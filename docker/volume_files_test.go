@@ -0,0 +1,31 @@
+package docker
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFileModeString(t *testing.T) {
+	tests := []struct {
+		mode os.FileMode
+		want string
+	}{
+		{0o644, "-rw-r--r--"},
+		{os.ModeDir | 0o755, "drwxr-xr-x"},
+		{os.ModeSymlink | 0o777, "lrwxrwxrwx"},
+		{os.ModeNamedPipe | 0o600, "prw-------"},
+		{os.ModeSocket | 0o755, "srwxr-xr-x"},
+		{os.ModeDevice | os.ModeCharDevice | 0o666, "crw-rw-rw-"},
+		{os.ModeDevice | 0o660, "brw-rw----"},
+		{os.ModeSetuid | 0o755, "-rwsr-xr-x"},
+		{os.ModeSetgid | 0o644, "-rw-r-Sr--"},
+		{os.ModeDir | os.ModeSticky | 0o777, "drwxrwxrwt"},
+		{os.ModeDir | os.ModeSticky | 0o770, "drwxrwx--T"},
+	}
+
+	for _, tt := range tests {
+		if got := fileModeString(tt.mode); got != tt.want {
+			t.Errorf("fileModeString(%v) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
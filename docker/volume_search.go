@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// maxSearchLineLength limits the length of matching lines sent back to clients
+const maxSearchLineLength = 1000
+
+// VolumeSearchOptions controls a search in a volume
+type VolumeSearchOptions struct {
+	Path        string // Directory to search in, "/" for the whole volume
+	Name        string // Glob matched against file names, e.g. "*.yml"
+	Query       string // Text searched for in file contents; without it, only names are matched
+	Regex       bool   // Query is an extended regular expression instead of literal text
+	MaxResults  int    // The search stops after this many matches
+	MaxFileSize int64  // Larger files are not searched for content
+}
+
+// searchCommand returns the command running a search in a helper container. Names are matched
+// by find, contents by grep, which skips binary files. Paths are NUL-terminated so that any
+// file name can be told apart from the matching line that follows it.
+func searchCommand(opts VolumeSearchOptions) []string {
+	// The search directory itself is not a match
+	cmd := []string{"find", volumeFilePath(opts.Path), "-mindepth", "1"}
+	if opts.Query == "" {
+		if opts.Name != "" {
+			cmd = append(cmd, "-name", opts.Name)
+		}
+		return append(cmd, "-print0")
+	}
+
+	cmd = append(cmd, "-type", "f", "!", "-size", fmt.Sprintf("+%dc", opts.MaxFileSize))
+	if opts.Name != "" {
+		cmd = append(cmd, "-name", opts.Name)
+	}
+	mode := "-F"
+	if opts.Regex {
+		mode = "-E"
+	}
+	return append(cmd, "-exec", "grep", "-n", "-H", "-I", "-Z", mode, "-e", opts.Query, "--", "{}", "+")
+}
+
+// searchVolume searches the volume mounted by a running helper container, reporting matches
+// through onMatch as they are found. The search stops after opts.MaxResults matches.
+func (c *Client) searchVolume(ctx context.Context, helperID string, opts VolumeSearchOptions, onMatch func(models.VolumeSearchMatch) error) (*models.VolumeSearchResult, error) {
+	stat, err := c.statVolumeFile(ctx, helperID, opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.Mode.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrNotDirectory, opts.Path)
+	}
+
+	exec, err := c.cli.ContainerExecCreate(ctx, helperID, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          searchCommand(opts),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec instance: %w", err)
+	}
+
+	resp, err := c.cli.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to exec instance: %w", err)
+	}
+	// Closing the output makes a search that is still running fail to write and exit
+	defer resp.Close()
+	stop := context.AfterFunc(ctx, resp.Close)
+	defer stop()
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	var stderr strings.Builder
+	copyDone := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(pw, &stderr, resp.Reader)
+		pw.CloseWithError(err)
+		copyDone <- err
+	}()
+
+	result := &models.VolumeSearchResult{Done: true}
+	output := bufio.NewReader(pr)
+	for {
+		match, err := readSearchMatch(output, opts.Query != "")
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read search output: %w", err)
+		}
+		if result.Matches == opts.MaxResults {
+			result.Truncated = true
+			return result, nil
+		}
+		if err := onMatch(match); err != nil {
+			return nil, err
+		}
+		result.Matches++
+	}
+	if err := <-copyDone; err != nil {
+		return nil, fmt.Errorf("failed to read search output: %w", err)
+	}
+
+	exitCode, err := c.execExitCode(ctx, exec.ID)
+	if err != nil {
+		return nil, err
+	}
+	// find also fails when grep found nothing in some of the files, so only a failure without
+	// any match that reported an error (e.g. an invalid regular expression) fails the search
+	if exitCode != 0 && result.Matches == 0 && stderr.Len() > 0 {
+		return nil, fmt.Errorf("search failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return result, nil
+}
+
+// readSearchMatch reads the next match from the output of a search command. Content matches
+// are followed by their line number and the matching line.
+func readSearchMatch(output *bufio.Reader, content bool) (models.VolumeSearchMatch, error) {
+	var match models.VolumeSearchMatch
+	p, err := output.ReadString(0)
+	if err == io.EOF && p == "" {
+		return match, io.EOF
+	}
+	if err != nil {
+		return match, unexpectedEOF(err)
+	}
+	match.Path = strings.TrimPrefix(strings.TrimSuffix(p, "\x00"), volumeMountPath)
+	if !content {
+		return match, nil
+	}
+
+	line, err := output.ReadString('\n')
+	if err != nil {
+		return match, unexpectedEOF(err)
+	}
+	number, text, _ := strings.Cut(strings.TrimSuffix(line, "\n"), ":")
+	if match.Line, err = strconv.Atoi(number); err != nil {
+		return match, fmt.Errorf("unexpected line number %q", number)
+	}
+	if len(text) > maxSearchLineLength {
+		text = strings.ToValidUTF8(text[:maxSearchLineLength], "")
+	}
+	match.Text = text
+	return match, nil
+}
+
+// unexpectedEOF reports output that ends within a match as truncated
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+package docker
+
+import (
+	"context"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// anonymousVolumeLabel marks volumes created without a name, e.g. for an image VOLUME
+const anonymousVolumeLabel = "com.docker.volume.anonymous"
+
+// CreateVolumeOptions holds the settings of a new volume
+type CreateVolumeOptions struct {
+	Name       string // Generated by Docker when empty
+	Driver     string // Defaults to "local"
+	DriverOpts map[string]string
+	Labels     map[string]string
+}
+
+// CreateVolume creates a volume
+func (c *Client) CreateVolume(ctx context.Context, opts CreateVolumeOptions) (*models.VolumeInfo, error) {
+	created, err := c.cli.VolumeCreate(ctx, volume.CreateOptions{
+		Name:       opts.Name,
+		Driver:     opts.Driver,
+		DriverOpts: opts.DriverOpts,
+		Labels:     opts.Labels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.VolumeInfo{
+		Name:       created.Name,
+		Driver:     created.Driver,
+		Mountpoint: created.Mountpoint,
+		CreatedAt:  created.CreatedAt,
+		Scope:      created.Scope,
+		Labels:     created.Labels,
+		Options:    created.Options,
+		Containers: []string{},
+	}, nil
+}
+
+// PruneVolumes removes volumes not used by any container. Like the daemon, only local volumes
+// without driver options are pruned, and only anonymous ones unless all is set. With dryRun
+// nothing is removed; the result lists the volumes that would be deleted and their sizes.
+func (c *Client) PruneVolumes(ctx context.Context, all, dryRun bool) (*models.VolumePruneResult, error) {
+	if dryRun {
+		return c.previewVolumePrune(ctx, all)
+	}
+
+	pruneFilters := filters.NewArgs()
+	if all {
+		pruneFilters.Add("all", "true")
+	}
+
+	report, err := c.cli.VolumesPrune(ctx, pruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	deleted := []string{}
+	deleted = append(deleted, report.VolumesDeleted...)
+	return &models.VolumePruneResult{
+		VolumesDeleted: deleted,
+		SpaceReclaimed: report.SpaceReclaimed,
+	}, nil
+}
+
+// previewVolumePrune selects the volumes a prune would remove using the same volume-to-container
+// mapping as ListVolumes, and estimates the reclaimed space from the daemon's disk usage data
+func (c *Client) previewVolumePrune(ctx context.Context, all bool) (*models.VolumePruneResult, error) {
+	volumes, err := c.cli.VolumeList(ctx, volume.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	volumeToContainers, err := c.volumeContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := c.cli.DiskUsage(ctx, types.DiskUsageOptions{Types: []types.DiskUsageObject{types.VolumeObject}})
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64, len(usage.Volumes))
+	for _, vol := range usage.Volumes {
+		if vol.UsageData != nil {
+			sizes[vol.Name] = vol.UsageData.Size
+		}
+	}
+
+	// Initialize as empty slices to ensure JSON marshals to [] instead of null
+	result := &models.VolumePruneResult{
+		DryRun:         true,
+		VolumesDeleted: []string{},
+		Volumes:        []models.PrunedVolume{},
+	}
+	for _, vol := range volumes.Volumes {
+		if len(volumeToContainers[vol.Name]) > 0 || !isPrunableVolume(vol, all) {
+			continue
+		}
+
+		size, ok := sizes[vol.Name]
+		if !ok {
+			size = -1
+		}
+		if size > 0 {
+			result.SpaceReclaimed += uint64(size)
+		}
+		result.Volumes = append(result.Volumes, models.PrunedVolume{
+			Name:      vol.Name,
+			CreatedAt: vol.CreatedAt,
+			Labels:    vol.Labels,
+			Size:      size,
+		})
+	}
+
+	sort.Slice(result.Volumes, func(i, j int) bool {
+		return result.Volumes[i].Name < result.Volumes[j].Name
+	})
+	for _, vol := range result.Volumes {
+		result.VolumesDeleted = append(result.VolumesDeleted, vol.Name)
+	}
+
+	return result, nil
+}
+
+// isPrunableVolume mirrors the daemon's prune criteria apart from the usage check
+func isPrunableVolume(vol *volume.Volume, all bool) bool {
+	if vol.Driver != "local" || len(vol.Options) > 0 {
+		return false
+	}
+	if !all {
+		_, anonymous := vol.Labels[anonymousVolumeLabel]
+		return anonymous
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/dev-zapi/docker-simple-panel/backups"
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// BackupHandler handles scheduled volume backup requests
+type BackupHandler struct {
+	scheduler *backups.Scheduler
+}
+
+// NewBackupHandler creates a new BackupHandler
+func NewBackupHandler(scheduler *backups.Scheduler) *BackupHandler {
+	return &BackupHandler{
+		scheduler: scheduler,
+	}
+}
+
+// BackupRunStarted is returned when a manual backup run has been accepted
+type BackupRunStarted struct {
+	RunID string `json:"run_id"`
+}
+
+// ListBackups handles getting the backup schedule, state and stored archives
+func (h *BackupHandler) ListBackups(w http.ResponseWriter, r *http.Request) {
+	status, err := h.scheduler.Status()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list backups: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    status,
+	})
+}
+
+// GetBackupHistory handles getting the results of past backup runs, newest first
+func (h *BackupHandler) GetBackupHistory(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    h.scheduler.History(),
+	})
+}
+
+// RunBackup handles starting a backup run. The "volume" query parameter (repeatable) backs up
+// the given volumes instead of the configured targets. The outcome is recorded in the history.
+func (h *BackupHandler) RunBackup(w http.ResponseWriter, r *http.Request) {
+	runID, err := h.scheduler.Trigger(r.URL.Query()["volume"])
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, backups.ErrRunInProgress) {
+			status = http.StatusConflict
+		}
+		respondWithError(w, status, "Failed to start backup: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, models.Response{
+		Success: true,
+		Message: "Backup started",
+		Data:    BackupRunStarted{RunID: runID},
+	})
+}
+
+// DownloadBackup handles downloading a stored backup archive
+func (h *BackupHandler) DownloadBackup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	archive, err := h.scheduler.OpenArchive(name)
+	if err != nil {
+		respondWithError(w, backupErrorStatus(err), "Failed to open backup: "+err.Error())
+		return
+	}
+	defer archive.Close()
+
+	info, err := archive.Stat()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to open backup: "+err.Error())
+		return
+	}
+
+	// Large archives take much longer to download than the server write timeout
+	extendWriteDeadline(w, 0)
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, archive); err != nil {
+		log.Printf("Failed to stream backup %s: %v", name, err)
+	}
+}
+
+// DeleteBackup handles deleting a stored backup archive
+func (h *BackupHandler) DeleteBackup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	if err := h.scheduler.DeleteArchive(name); err != nil {
+		respondWithError(w, backupErrorStatus(err), "Failed to delete backup: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Backup deleted successfully",
+	})
+}
+
+// backupErrorStatus maps a backup archive error to an HTTP status code
+func backupErrorStatus(err error) int {
+	if errors.Is(err, backups.ErrArchiveNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dev-zapi/docker-simple-panel/docker"
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// maxBuildContextSize limits the size of an uploaded build context
+const maxBuildContextSize = 2 << 30
+
+// ImageBuildStarted is returned when a build has been accepted
+type ImageBuildStarted struct {
+	BuildID string `json:"build_id"`
+}
+
+// BuildImage handles uploading a build context and starting an image build.
+// The body is either a tar archive (optionally gzip-compressed) or a multipart form whose
+// file parts are placed in the context at their file name, e.g. "Dockerfile" or "src/app.py".
+// Query parameters: tag (repeatable), target, dockerfile, build_arg (repeatable KEY=VALUE),
+// no_cache and pull. The build output is streamed by StreamImageBuild.
+func (h *DockerHandler) BuildImage(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseBuildOptions(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid build options: "+err.Error())
+		return
+	}
+
+	// Claim a job slot before the upload, so a rejected build does not transfer its context
+	release, err := h.imageJobs.acquire()
+	if err != nil {
+		respondWithError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+
+	// Uploading a large context can take much longer than the server timeouts
+	extendReadDeadline(w, imageUploadTimeout)
+	extendWriteDeadline(w, imageUploadTimeout)
+	r.Body = http.MaxBytesReader(w, r.Body, maxBuildContextSize)
+
+	contextFile, err := os.CreateTemp("", "dsp-upload-*.tar")
+	if err != nil {
+		release()
+		respondWithError(w, http.StatusInternalServerError, "Failed to store build context: "+err.Error())
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		err = writeMultipartContext(r, contextFile)
+	} else {
+		_, err = io.Copy(contextFile, r.Body)
+	}
+	if err != nil {
+		contextFile.Close()
+		os.Remove(contextFile.Name())
+		release()
+		respondWithError(w, http.StatusBadRequest, "Failed to read build context: "+err.Error())
+		return
+	}
+
+	buildID, err := h.startImageJob(contextFile, release, func(ctx context.Context, input io.Reader, onLine func(string) error) (interface{}, error) {
+		imageID, err := h.manager.BuildImage(ctx, input, opts, onLine)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"image_id": imageID}, nil
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to start build: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, models.Response{
+		Success: true,
+		Message: "Build started",
+		Data:    ImageBuildStarted{BuildID: buildID},
+	})
+}
+
+// StreamImageBuild handles WebSocket connections for following the output of a build.
+// Output lines are sent as text messages, starting from the beginning of the build. The last
+// message is {"image_id": "..."} on success or {"error": "..."} on failure.
+func (h *DockerHandler) StreamImageBuild(w http.ResponseWriter, r *http.Request) {
+	h.streamImageJob(w, r, "Build")
+}
+
+// parseBuildOptions reads the build settings from the query string
+func parseBuildOptions(r *http.Request) (docker.BuildOptions, error) {
+	query := r.URL.Query()
+	opts := docker.BuildOptions{
+		Target:     query.Get("target"),
+		Dockerfile: query.Get("dockerfile"),
+		BuildArgs:  make(map[string]*string),
+	}
+
+	for _, tag := range query["tag"] {
+		if _, err := docker.ParseImageReference(tag, "", ""); err != nil {
+			return opts, fmt.Errorf("invalid tag: %v", err)
+		}
+		opts.Tags = append(opts.Tags, tag)
+	}
+
+	for _, arg := range query["build_arg"] {
+		key, value, hasValue := strings.Cut(arg, "=")
+		if key == "" {
+			return opts, fmt.Errorf("invalid build_arg %q: expected KEY=VALUE", arg)
+		}
+		if hasValue {
+			opts.BuildArgs[key] = &value
+		} else {
+			opts.BuildArgs[key] = nil
+		}
+	}
+
+	if opts.Dockerfile != "" && !isValidContextPath(opts.Dockerfile) {
+		return opts, errors.New("invalid dockerfile path")
+	}
+
+	var err error
+	if opts.NoCache, err = parseBoolQuery(r, "no_cache", false); err != nil {
+		return opts, errors.New("invalid no_cache parameter")
+	}
+	if opts.Pull, err = parseBoolQuery(r, "pull", false); err != nil {
+		return opts, errors.New("invalid pull parameter")
+	}
+	return opts, nil
+}
+
+// isValidContextPath validates a relative path inside a build context
+func isValidContextPath(p string) bool {
+	return p != "" && !strings.HasPrefix(p, "/") && isValidPath("/"+p)
+}
+
+// writeMultipartContext stores the uploaded files in a temporary directory and writes them to
+// dst as a tar archive. Files are placed at their (relative) file name.
+func writeMultipartContext(r *http.Request, dst io.Writer) error {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "dsp-build-context-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	files := 0
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// part.FileName() drops directories, so the original name is read from the header
+		_, params, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+		if params["filename"] == "" {
+			part.Close()
+			continue
+		}
+		name := path.Clean(filepath.ToSlash(params["filename"]))
+		if !isValidContextPath(name) {
+			part.Close()
+			return fmt.Errorf("invalid file name %q", params["filename"])
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			part.Close()
+			return err
+		}
+		file, err := os.Create(target)
+		if err != nil {
+			part.Close()
+			return err
+		}
+		_, err = io.Copy(file, part)
+		file.Close()
+		part.Close()
+		if err != nil {
+			return err
+		}
+		files++
+	}
+
+	if files == 0 {
+		return errors.New("no files uploaded")
+	}
+
+	tw := tar.NewWriter(dst)
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == dir {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		file, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/dev-zapi/docker-simple-panel/docker"
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// ListComposeProjects handles listing Docker Compose projects with their services
+func (h *DockerHandler) ListComposeProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := h.manager.ListComposeProjects(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list compose projects: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    projects,
+	})
+}
+
+// GetComposeProject handles getting a single Docker Compose project
+func (h *DockerHandler) GetComposeProject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["name"]
+
+	if projectName == "" {
+		respondWithError(w, http.StatusBadRequest, "Project name is required")
+		return
+	}
+
+	project, err := h.manager.GetComposeProject(r.Context(), projectName)
+	if err != nil {
+		if errors.Is(err, docker.ErrComposeProjectNotFound) {
+			respondWithError(w, http.StatusNotFound, "Compose project not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to get compose project: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    project,
+	})
+}
+
+// ComposeProjectAction handles starting, stopping or restarting all containers of a Docker Compose project.
+// The response lists the result for each container; success is false if any container failed.
+func (h *DockerHandler) ComposeProjectAction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["name"]
+	action := vars["action"]
+
+	if projectName == "" {
+		respondWithError(w, http.StatusBadRequest, "Project name is required")
+		return
+	}
+
+	switch action {
+	case docker.ComposeActionStart, docker.ComposeActionStop, docker.ComposeActionRestart:
+	default:
+		respondWithError(w, http.StatusBadRequest, "Unsupported action: "+action)
+		return
+	}
+
+	timeout, err := parseStopTimeout(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid timeout: "+err.Error())
+		return
+	}
+
+	project, err := h.manager.GetComposeProject(r.Context(), projectName)
+	if err != nil {
+		if errors.Is(err, docker.ErrComposeProjectNotFound) {
+			respondWithError(w, http.StatusNotFound, "Compose project not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to get compose project: "+err.Error())
+		return
+	}
+
+	// Containers are stopped one after another, which can take much longer than the server write timeout
+	if action != docker.ComposeActionStart {
+		extendStopDeadline(w, h.manager.ComposeStopTimeout(r.Context(), project, timeout, h.configManager.GetStopTimeout()))
+	}
+
+	results, err := h.manager.ComposeProjectAction(r.Context(), project, action, timeout, h.configManager.GetStopTimeout())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to "+action+" compose project: "+err.Error())
+		return
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+
+	message := fmt.Sprintf("Compose project %s: %s completed for %d container(s)", projectName, action, len(results))
+	if failed > 0 {
+		message = fmt.Sprintf("Compose project %s: %s failed for %d of %d container(s)", projectName, action, failed, len(results))
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: failed == 0,
+		Message: message,
+		Data:    results,
+	})
+}
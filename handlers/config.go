@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"path/filepath"
 
 	"github.com/dev-zapi/docker-simple-panel/config"
 	"github.com/dev-zapi/docker-simple-panel/models"
@@ -35,6 +36,9 @@ type UpdateConfigRequest struct {
 	DockerSocket        *string `json:"docker_socket,omitempty"`
 	LogLevel            *string `json:"log_level,omitempty"`
 	VolumeExplorerImage *string `json:"volume_explorer_image,omitempty"`
+	StopTimeout         *int    `json:"stop_timeout,omitempty"`
+	StacksDir           *string `json:"stacks_dir,omitempty"`
+	StacksHostDir       *string `json:"stacks_host_dir,omitempty"`
 	SessionMaxTimeout   *int    `json:"session_max_timeout,omitempty"`
 }
 
@@ -71,6 +75,42 @@ func (h *ConfigHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Update default stop timeout if provided
+	if req.StopTimeout != nil {
+		if *req.StopTimeout <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Stop timeout must be a positive number of seconds")
+			return
+		}
+		if err := h.configManager.SetStopTimeout(*req.StopTimeout); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to update stop timeout: "+err.Error())
+			return
+		}
+	}
+
+	// Update stacks directory if provided
+	if req.StacksDir != nil {
+		if *req.StacksDir == "" {
+			respondWithError(w, http.StatusBadRequest, "Stacks directory cannot be empty")
+			return
+		}
+		if err := h.configManager.SetStacksDir(*req.StacksDir); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to update stacks directory: "+err.Error())
+			return
+		}
+	}
+
+	// Update stacks host directory if provided; an empty value clears it
+	if req.StacksHostDir != nil {
+		if *req.StacksHostDir != "" && !filepath.IsAbs(*req.StacksHostDir) {
+			respondWithError(w, http.StatusBadRequest, "Stacks host directory must be an absolute path")
+			return
+		}
+		if err := h.configManager.SetStacksHostDir(*req.StacksHostDir); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to update stacks host directory: "+err.Error())
+			return
+		}
+	}
+
 	// Update session max timeout if provided
 	if req.SessionMaxTimeout != nil {
 		if err := h.configManager.SetSessionMaxTimeout(*req.SessionMaxTimeout); err != nil {
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/gorilla/mux"
+
+	"github.com/dev-zapi/docker-simple-panel/docker"
+	"github.com/dev-zapi/docker-simple-panel/middleware"
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+const (
+	// containerListTimeout bounds how long listing a container directory through the archive API may take
+	containerListTimeout = time.Minute
+	// containerChangeSizesTimeout bounds how long looking up the sizes of container changes may take
+	containerChangeSizesTimeout = time.Minute
+)
+
+// containerFileErrorStatus maps a container file operation error to an HTTP status code
+func containerFileErrorStatus(err error) int {
+	switch {
+	case client.IsErrNotFound(err):
+		return http.StatusNotFound
+	case errors.Is(err, docker.ErrFileExists):
+		return http.StatusConflict
+	case errors.Is(err, docker.ErrNotRegularFile), errors.Is(err, docker.ErrNotDirectory), errors.Is(err, docker.ErrInvalidArchive):
+		return http.StatusBadRequest
+	}
+	return dockerErrorStatus(err)
+}
+
+// ListContainerFiles handles listing a directory in the filesystem of a container
+func (h *DockerHandler) ListContainerFiles(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	// Get path from query parameter, default to root
+	dirPath := r.URL.Query().Get("path")
+	if dirPath == "" {
+		dirPath = "/"
+	}
+
+	// Validate path to prevent directory traversal attacks
+	if !isValidPath(dirPath) {
+		respondWithError(w, http.StatusBadRequest, "Invalid path")
+		return
+	}
+	dirPath = path.Clean(dirPath)
+
+	// Listing through the archive API reads the whole subtree, which can take longer than the
+	// server write timeout
+	extendWriteDeadline(w, containerListTimeout+10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), containerListTimeout)
+	defer cancel()
+
+	files, err := h.manager.ListContainerFiles(ctx, containerID, dirPath)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			respondWithError(w, http.StatusGatewayTimeout, fmt.Sprintf("Failed to list container files: directory could not be read within %s", containerListTimeout))
+			return
+		}
+		respondWithError(w, containerFileErrorStatus(err), "Failed to list container files: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    files,
+	})
+}
+
+// DownloadContainerFile handles streaming a file from the filesystem of a container
+func (h *DockerHandler) DownloadContainerFile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	filePath := r.URL.Query().Get("path")
+	if filePath == "" {
+		respondWithError(w, http.StatusBadRequest, "File path is required")
+		return
+	}
+
+	// Validate path to prevent directory traversal attacks
+	if !isValidPath(filePath) {
+		respondWithError(w, http.StatusBadRequest, "Invalid path")
+		return
+	}
+	filePath = path.Clean(filePath)
+
+	file, err := h.manager.OpenContainerFile(r.Context(), containerID, filePath)
+	if err != nil {
+		respondWithError(w, containerFileErrorStatus(err), "Failed to download file: "+err.Error())
+		return
+	}
+	defer file.Close()
+
+	serveFileStream(w, r, file, fmt.Sprintf("file %s of container %s", filePath, containerID))
+}
+
+// UploadContainerFiles handles copying files into a directory of a container. The body is either
+// a multipart form, whose files are written under their base names, or a tar archive (optionally
+// gzip or bzip2-compressed) extracted into the directory. Existing files are only replaced with
+// overwrite=true; uid, gid and mode (octal) set the owner and permissions of the copied files.
+// Every copy is logged with the user who made it.
+func (h *DockerHandler) UploadContainerFiles(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	dir := r.URL.Query().Get("path")
+	if dir == "" {
+		respondWithError(w, http.StatusBadRequest, "Target directory is required")
+		return
+	}
+
+	// Validate path to prevent directory traversal attacks
+	if !isValidPath(dir) {
+		respondWithError(w, http.StatusBadRequest, "Invalid path")
+		return
+	}
+	dir = path.Clean(dir)
+
+	opts, err := parseContainerUploadOptions(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid upload options: "+err.Error())
+		return
+	}
+
+	// Uploads into containers are bounded like uploads into volumes
+	extendReadDeadline(w, volumeUploadTimeout)
+	extendWriteDeadline(w, volumeUploadTimeout)
+	r.Body = http.MaxBytesReader(w, r.Body, maxVolumeUploadSize)
+
+	// The upload is stored first so nothing is copied if it fails
+	tempDir, err := os.MkdirTemp("", "dsp-container-upload-*")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to store upload: "+err.Error())
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	var result *models.ContainerUploadResult
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		files, spoolErr := spoolUploadedFiles(r, tempDir)
+		if spoolErr != nil {
+			respondWithError(w, http.StatusBadRequest, "Failed to read upload: "+spoolErr.Error())
+			return
+		}
+		defer func() {
+			for _, file := range files {
+				file.Content.(*os.File).Close()
+			}
+		}()
+		result, err = h.manager.UploadContainerFiles(r.Context(), containerID, dir, files, opts)
+	} else {
+		archive, createErr := os.CreateTemp(tempDir, "archive-*")
+		if createErr != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to store upload: "+createErr.Error())
+			return
+		}
+		defer archive.Close()
+		if _, err := io.Copy(archive, r.Body); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Failed to read upload: "+err.Error())
+			return
+		}
+		if _, err := archive.Seek(0, io.SeekStart); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to store upload: "+err.Error())
+			return
+		}
+		result, err = h.manager.ExtractContainerArchive(r.Context(), containerID, dir, archive, opts)
+	}
+
+	username := middleware.Username(r)
+	if err != nil {
+		log.Printf("Audit: user %q failed to copy files into %s of container %s: %v", username, dir, containerID, err)
+		respondWithError(w, containerFileErrorStatus(err), "Failed to copy files: "+err.Error())
+		return
+	}
+	log.Printf("Audit: user %q copied %d file(s) into %s of container %s (%d overwritten)",
+		username, len(result.Files), dir, containerID, len(result.Overwritten))
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: fmt.Sprintf("Copied %d file(s)", len(result.Files)),
+		Data:    result,
+	})
+}
+
+// parseContainerUploadOptions reads the overwrite, uid, gid and mode query parameters
+func parseContainerUploadOptions(r *http.Request) (docker.ContainerUploadOptions, error) {
+	var opts docker.ContainerUploadOptions
+	var err error
+	if opts.Overwrite, err = parseBoolQuery(r, "overwrite", false); err != nil {
+		return opts, errors.New("overwrite must be true or false")
+	}
+
+	query := r.URL.Query()
+	for _, param := range []struct {
+		name   string
+		target **int
+	}{{"uid", &opts.UID}, {"gid", &opts.GID}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id < 0 {
+			return opts, fmt.Errorf("%s must be a non-negative number", param.name)
+		}
+		*param.target = &id
+	}
+
+	if value := query.Get("mode"); value != "" {
+		mode, err := strconv.ParseInt(value, 8, 64)
+		if err != nil || mode < 0 || mode > 07777 {
+			return opts, errors.New("mode must be an octal permission between 0000 and 7777")
+		}
+		opts.Mode = &mode
+	}
+	return opts, nil
+}
+
+// GetContainerChanges handles listing the paths changed in a container's writable layer as a
+// tree. With sizes=true, the sizes of changed files and of the writable layer are included.
+func (h *DockerHandler) GetContainerChanges(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	sizes, err := parseBoolQuery(r, "sizes", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid sizes parameter")
+		return
+	}
+
+	ctx := r.Context()
+	if sizes {
+		// Size lookups make a request per changed path, which can take longer than the server
+		// write timeout
+		extendWriteDeadline(w, containerChangeSizesTimeout+10*time.Second)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, containerChangeSizesTimeout)
+		defer cancel()
+	}
+
+	changes, err := h.manager.ContainerChanges(ctx, containerID, sizes)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			respondWithError(w, http.StatusGatewayTimeout, fmt.Sprintf("Failed to get container changes: sizes could not be looked up within %s", containerChangeSizesTimeout))
+			return
+		}
+		respondWithError(w, containerFileErrorStatus(err), "Failed to get container changes: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    changes,
+	})
+}
@@ -3,13 +3,16 @@ package handlers
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
@@ -19,10 +22,18 @@ import (
 	"github.com/dev-zapi/docker-simple-panel/models"
 )
 
+const (
+	// defaultRecreateHealthTimeout is how long a recreated container has to become healthy
+	defaultRecreateHealthTimeout = 60 * time.Second
+	// recreateOperationTimeout bounds the pull, stop and create steps of a recreate
+	recreateOperationTimeout = 15 * time.Minute
+)
+
 // DockerHandler handles Docker-related requests
 type DockerHandler struct {
 	manager       *docker.Manager
 	configManager *config.Manager
+	imageJobs     *imageJobRegistry
 }
 
 // NewDockerHandler creates a new DockerHandler
@@ -30,6 +41,7 @@ func NewDockerHandler(manager *docker.Manager, configManager *config.Manager) *D
 	return &DockerHandler{
 		manager:       manager,
 		configManager: configManager,
+		imageJobs:     newImageJobRegistry(),
 	}
 }
 
@@ -100,8 +112,18 @@ func (h *DockerHandler) StopContainer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.manager.StopContainer(r.Context(), containerID); err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to stop container: "+err.Error())
+	timeout, err := parseStopTimeout(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid timeout: "+err.Error())
+		return
+	}
+
+	// Waiting for the container to stop can take longer than the server write timeout
+	resolved := h.manager.ResolveStopTimeout(r.Context(), containerID, timeout, h.configManager.GetStopTimeout())
+	extendStopDeadline(w, resolved)
+
+	if err := h.manager.StopContainer(r.Context(), containerID, &resolved, h.configManager.GetStopTimeout()); err != nil {
+		respondWithError(w, dockerErrorStatus(err), "Failed to stop container: "+err.Error())
 		return
 	}
 
@@ -121,8 +143,18 @@ func (h *DockerHandler) RestartContainer(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.manager.RestartContainer(r.Context(), containerID); err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to restart container: "+err.Error())
+	timeout, err := parseStopTimeout(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid timeout: "+err.Error())
+		return
+	}
+
+	// Waiting for the container to stop can take longer than the server write timeout
+	resolved := h.manager.ResolveStopTimeout(r.Context(), containerID, timeout, h.configManager.GetStopTimeout())
+	extendStopDeadline(w, resolved)
+
+	if err := h.manager.RestartContainer(r.Context(), containerID, &resolved, h.configManager.GetStopTimeout()); err != nil {
+		respondWithError(w, dockerErrorStatus(err), "Failed to restart container: "+err.Error())
 		return
 	}
 
@@ -132,6 +164,168 @@ func (h *DockerHandler) RestartContainer(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// PauseContainer handles pausing a container
+func (h *DockerHandler) PauseContainer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	if err := h.manager.PauseContainer(r.Context(), containerID); err != nil {
+		respondWithError(w, dockerErrorStatus(err), "Failed to pause container: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Container paused successfully",
+	})
+}
+
+// UnpauseContainer handles unpausing a container
+func (h *DockerHandler) UnpauseContainer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	if err := h.manager.UnpauseContainer(r.Context(), containerID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to unpause container: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Container unpaused successfully",
+	})
+}
+
+// KillContainer handles sending a signal to a container.
+// The signal is taken from the "signal" query parameter (e.g. SIGHUP, HUP or 1) and defaults to SIGKILL.
+func (h *DockerHandler) KillContainer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	signal := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("signal")))
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+	if !isValidSignal(signal) {
+		respondWithError(w, http.StatusBadRequest, "Invalid signal")
+		return
+	}
+
+	if err := h.manager.KillContainer(r.Context(), containerID, signal); err != nil {
+		respondWithError(w, dockerErrorStatus(err), "Failed to kill container: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Signal " + signal + " sent to container successfully",
+	})
+}
+
+// RemoveContainer handles removing a container.
+// Query parameters "force" and "remove_volumes" control forced removal of a running
+// container and removal of its anonymous volumes.
+func (h *DockerHandler) RemoveContainer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	force, err := parseBoolQuery(r, "force", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid force parameter")
+		return
+	}
+
+	removeVolumes, err := parseBoolQuery(r, "remove_volumes", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid remove_volumes parameter")
+		return
+	}
+
+	if err := h.manager.RemoveContainer(r.Context(), containerID, force, removeVolumes); err != nil {
+		respondWithError(w, dockerErrorStatus(err), "Failed to remove container: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Container removed successfully",
+	})
+}
+
+// RecreateContainer handles recreating a container with the same settings, optionally pulling
+// its image first. Query parameters: pull, timeout (stop timeout) and health_timeout (seconds
+// the new container has to start and become healthy before the old one is restored).
+func (h *DockerHandler) RecreateContainer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	pull, err := parseBoolQuery(r, "pull", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pull parameter")
+		return
+	}
+
+	timeout, err := parseStopTimeout(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid timeout: "+err.Error())
+		return
+	}
+
+	healthTimeout := defaultRecreateHealthTimeout
+	if value := r.URL.Query().Get("health_timeout"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid health_timeout: must be a positive number of seconds")
+			return
+		}
+		healthTimeout = time.Duration(seconds) * time.Second
+	}
+
+	// Pulling and waiting for the health check can take much longer than the server write timeout
+	extendWriteDeadline(w, recreateOperationTimeout+healthTimeout)
+
+	result, err := h.manager.RecreateContainer(r.Context(), containerID, pull, timeout, h.configManager.GetStopTimeout(), healthTimeout)
+	if err != nil {
+		respondWithJSON(w, dockerErrorStatus(err), models.Response{
+			Success: false,
+			Message: "Failed to recreate container: " + err.Error(),
+			Data:    result,
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Container recreated successfully",
+		Data:    result,
+	})
+}
+
 // HealthCheck handles health check requests
 func (h *DockerHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	if err := h.manager.Ping(r.Context()); err != nil {
@@ -159,6 +353,76 @@ func (h *DockerHandler) ListVolumes(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// CreateVolumeRequest represents a volume creation request
+type CreateVolumeRequest struct {
+	Name       string            `json:"name,omitempty"`   // Generated by Docker when empty
+	Driver     string            `json:"driver,omitempty"` // Defaults to "local"
+	DriverOpts map[string]string `json:"driver_opts,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+// CreateVolume handles creating a Docker volume
+func (h *DockerHandler) CreateVolume(w http.ResponseWriter, r *http.Request) {
+	var req CreateVolumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	volume, err := h.manager.CreateVolume(r.Context(), docker.CreateVolumeOptions{
+		Name:       req.Name,
+		Driver:     req.Driver,
+		DriverOpts: req.DriverOpts,
+		Labels:     req.Labels,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errdefs.IsInvalidParameter(err) {
+			status = http.StatusBadRequest
+		}
+		respondWithError(w, status, "Failed to create volume: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Volume created successfully",
+		Data:    volume,
+	})
+}
+
+// PruneVolumes handles removing volumes not used by any container.
+// Only anonymous volumes are removed unless all=true; dry_run=true only reports what would be removed.
+func (h *DockerHandler) PruneVolumes(w http.ResponseWriter, r *http.Request) {
+	all, err := parseBoolQuery(r, "all", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid all parameter")
+		return
+	}
+	dryRun, err := parseBoolQuery(r, "dry_run", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid dry_run parameter")
+		return
+	}
+
+	result, err := h.manager.PruneVolumes(r.Context(), all, dryRun)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to prune volumes: "+err.Error())
+		return
+	}
+
+	message := fmt.Sprintf("Removed %d volume(s)", len(result.VolumesDeleted))
+	if dryRun {
+		message = fmt.Sprintf("%d volume(s) would be removed", len(result.VolumesDeleted))
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: message,
+		Data:    result,
+	})
+}
+
 // DeleteVolume handles deleting a Docker volume by name
 func (h *DockerHandler) DeleteVolume(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -223,40 +487,7 @@ func (h *DockerHandler) StreamContainerLogs(w http.ResponseWriter, r *http.Reque
 	}
 	defer logReader.Close()
 
-	// Create channels for coordination
-	done := make(chan struct{})
-	var doneOnce sync.Once
-	
-	// Goroutine to handle WebSocket pings to keep connection alive
-	go func() {
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
-		
-		for {
-			select {
-			case <-done:
-				return
-			case <-ticker.C:
-				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-					log.Printf("Failed to send ping: %v", err)
-					cancel()
-					return
-				}
-			}
-		}
-	}()
-
-	// Goroutine to handle client disconnection
-	go func() {
-		for {
-			if _, _, err := conn.ReadMessage(); err != nil {
-				log.Printf("WebSocket read error (client disconnect): %v", err)
-				cancel()
-				doneOnce.Do(func() { close(done) })
-				return
-			}
-		}
-	}()
+	keepAliveWebSocket(ctx, cancel, conn)
 
 	// Use Docker's stdcopy to properly demultiplex stdout and stderr streams
 	// Create pipes to separate stdout and stderr
@@ -382,7 +613,7 @@ func (h *DockerHandler) ExploreVolumeFiles(w http.ResponseWriter, r *http.Reques
 	
 	files, err := h.manager.ExploreVolumeFiles(r.Context(), volumeName, path, explorerImage)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to explore volume: "+err.Error())
+		respondWithError(w, volumeFileErrorStatus(err), "Failed to explore volume: "+err.Error())
 		return
 	}
 	
@@ -420,7 +651,7 @@ func (h *DockerHandler) ReadVolumeFile(w http.ResponseWriter, r *http.Request) {
 	
 	content, err := h.manager.ReadVolumeFile(r.Context(), volumeName, filePath, explorerImage)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to read file: "+err.Error())
+		respondWithError(w, volumeFileErrorStatus(err), "Failed to read file: "+err.Error())
 		return
 	}
 	
@@ -448,3 +679,27 @@ func isValidPath(path string) bool {
 	
 	return true
 }
+
+// parseStopTimeout parses the optional "timeout" query parameter (in seconds)
+func parseStopTimeout(r *http.Request) (*int, error) {
+	value := r.URL.Query().Get("timeout")
+	if value == "" {
+		return nil, nil
+	}
+
+	timeout, err := strconv.Atoi(value)
+	if err != nil || timeout < 0 {
+		return nil, fmt.Errorf("must be a non-negative number of seconds")
+	}
+	return &timeout, nil
+}
+
+// isValidSignal validates a signal name or number such as SIGHUP, HUP or 9
+func isValidSignal(signal string) bool {
+	for _, r := range strings.TrimPrefix(signal, "SIG") {
+		if !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && r != '+' && r != '-' {
+			return false
+		}
+	}
+	return signal != "" && signal != "SIG"
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// defaultExecCommand is the command started when the client does not specify one
+const defaultExecCommand = "/bin/sh"
+
+// wsWriter serializes writes of container output to a WebSocket connection
+type wsWriter struct {
+	mu   *sync.Mutex
+	conn *websocket.Conn
+}
+
+// Write sends p as a single binary WebSocket message
+func (w *wsWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.conn.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ExecContainer handles WebSocket connections for an interactive exec session in a container.
+//
+// Query parameters:
+//   - cmd: command to run (default /bin/sh), split on whitespace
+//   - tty: allocate a TTY (default true)
+//   - cols, rows: initial terminal size
+//
+// Client messages are JSON-encoded models.ExecMessage values ("input" or "resize");
+// binary messages are forwarded to stdin as-is. Output is sent as binary messages.
+func (h *DockerHandler) ExecContainer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	query := r.URL.Query()
+	cmd := strings.Fields(query.Get("cmd"))
+	if len(cmd) == 0 {
+		cmd = []string{defaultExecCommand}
+	}
+
+	tty, err := parseBoolQuery(r, "tty", true)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid tty parameter")
+		return
+	}
+
+	// Upgrade HTTP connection to WebSocket
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade to WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Create context with cancel for cleanup
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	var writeMu sync.Mutex
+	writeJSON := func(v interface{}) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.WriteJSON(v)
+	}
+
+	execID, err := h.manager.CreateExec(ctx, containerID, cmd, tty)
+	if err != nil {
+		writeJSON(map[string]string{
+			"error": "Failed to create exec session: " + err.Error(),
+		})
+		return
+	}
+
+	hijacked, err := h.manager.AttachExec(ctx, execID, tty)
+	if err != nil {
+		writeJSON(map[string]string{
+			"error": "Failed to attach to exec session: " + err.Error(),
+		})
+		return
+	}
+	defer hijacked.Close()
+
+	// Apply the initial terminal size if provided
+	if tty {
+		cols, _ := strconv.ParseUint(query.Get("cols"), 10, 32)
+		rows, _ := strconv.ParseUint(query.Get("rows"), 10, 32)
+		if cols > 0 && rows > 0 {
+			if err := h.manager.ResizeExec(ctx, execID, uint(rows), uint(cols)); err != nil {
+				log.Printf("Failed to set initial exec size: %v", err)
+			}
+		}
+	}
+
+	// The session ends when the process exits or the client disconnects
+	session, finish := context.WithCancel(ctx)
+	defer finish()
+	pingWebSocket(session, finish, conn)
+
+	// Goroutine to forward client input and resize messages to the exec session
+	go func() {
+		defer finish()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+					log.Printf("WebSocket read error (client disconnect): %v", err)
+				}
+				return
+			}
+
+			if messageType == websocket.BinaryMessage {
+				if _, err := hijacked.Conn.Write(data); err != nil {
+					return
+				}
+				continue
+			}
+
+			var msg models.ExecMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				log.Printf("Ignoring malformed exec message: %v", err)
+				continue
+			}
+
+			switch msg.Type {
+			case "input":
+				if _, err := io.WriteString(hijacked.Conn, msg.Data); err != nil {
+					return
+				}
+			case "resize":
+				if !tty || msg.Cols == 0 || msg.Rows == 0 {
+					continue
+				}
+				if err := h.manager.ResizeExec(ctx, execID, msg.Rows, msg.Cols); err != nil {
+					log.Printf("Failed to resize exec session: %v", err)
+				}
+			}
+		}
+	}()
+
+	// Goroutine to forward exec output to the WebSocket
+	go func() {
+		defer finish()
+		out := &wsWriter{mu: &writeMu, conn: conn}
+		var err error
+		if tty {
+			_, err = io.Copy(out, hijacked.Reader)
+		} else {
+			_, err = stdcopy.StdCopy(out, out, hijacked.Reader)
+		}
+		if err != nil && err != io.EOF {
+			select {
+			case <-session.Done():
+			default:
+				log.Printf("Error reading exec output: %v", err)
+			}
+		}
+	}()
+
+	<-session.Done()
+
+	// Close stdin so the process sees EOF, then report how it ended
+	hijacked.CloseWrite()
+
+	inspectCtx, inspectCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer inspectCancel()
+	if inspect, err := h.manager.InspectExec(inspectCtx, execID); err == nil && !inspect.Running {
+		writeJSON(map[string]int{
+			"exit_code": inspect.ExitCode,
+		})
+	}
+
+	writeMu.Lock()
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	writeMu.Unlock()
+}
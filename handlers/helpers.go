@@ -1,9 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/gorilla/websocket"
+
+	"github.com/dev-zapi/docker-simple-panel/docker"
 	"github.com/dev-zapi/docker-simple-panel/models"
 )
 
@@ -21,3 +29,98 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.WriteHeader(code)
 	json.NewEncoder(w).Encode(payload)
 }
+
+// extendWriteDeadline lifts the server write timeout for long-running requests.
+// A zero duration removes the deadline entirely.
+func extendWriteDeadline(w http.ResponseWriter, d time.Duration) {
+	deadline := time.Time{}
+	if d > 0 {
+		deadline = time.Now().Add(d)
+	}
+	if err := http.NewResponseController(w).SetWriteDeadline(deadline); err != nil {
+		log.Printf("Warning: failed to extend write deadline: %v", err)
+	}
+}
+
+// pingWebSocket pings the client every 30 seconds to keep the connection alive until ctx is
+// done. If a ping fails, the connection is considered lost and cancel is called. Pings are
+// control messages, which may be written concurrently with the handler's own messages.
+func pingWebSocket(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn) {
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+					log.Printf("Failed to send ping: %v", err)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+}
+
+// keepAliveWebSocket pings the client like pingWebSocket for handlers that only send messages.
+// Incoming messages are discarded, and cancel is called once the client disconnects.
+func keepAliveWebSocket(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn) {
+	pingWebSocket(ctx, cancel, conn)
+
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+}
+
+// dockerErrorStatus maps a Docker operation error to an HTTP status code
+func dockerErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, docker.ErrSelfOperation):
+		return http.StatusForbidden
+	case errors.Is(err, docker.ErrRecreateAutoRemove):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// parseBoolQuery parses a boolean query parameter, returning defaultValue when it is absent
+func parseBoolQuery(r *http.Request, name string, defaultValue bool) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// stopDeadlineMargin is added to stop timeouts for the time Docker needs beyond waiting for containers
+const stopDeadlineMargin = 30 * time.Second
+
+// extendStopDeadline lifts the server write timeout for requests that stop containers with the
+// given stop timeout in seconds. A negative timeout waits indefinitely and removes the deadline.
+func extendStopDeadline(w http.ResponseWriter, timeout int) {
+	if timeout < 0 {
+		extendWriteDeadline(w, 0)
+		return
+	}
+	extendWriteDeadline(w, time.Duration(timeout)*time.Second+stopDeadlineMargin)
+}
+
+// extendReadDeadline lifts the server read timeout for large uploads.
+// A zero duration removes the deadline entirely.
+func extendReadDeadline(w http.ResponseWriter, d time.Duration) {
+	deadline := time.Time{}
+	if d > 0 {
+		deadline = time.Now().Add(d)
+	}
+	if err := http.NewResponseController(w).SetReadDeadline(deadline); err != nil {
+		log.Printf("Warning: failed to extend read deadline: %v", err)
+	}
+}
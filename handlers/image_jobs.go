@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// imageUploadTimeout bounds how long uploading a build context or image archive may take
+	imageUploadTimeout = 30 * time.Minute
+	// imageJobTimeout bounds how long a single build or import may run
+	imageJobTimeout = 2 * time.Hour
+	// imageJobRetention is how long the output of a finished job is kept for late clients
+	imageJobRetention = 10 * time.Minute
+	// maxImageJobOutputLines is how many output lines are kept per job; older lines are dropped
+	maxImageJobOutputLines = 5000
+	// maxConcurrentImageJobs limits how many builds and imports may run at the same time
+	maxConcurrentImageJobs = 4
+)
+
+// errTooManyImageJobs is returned when maxConcurrentImageJobs jobs are already running
+var errTooManyImageJobs = fmt.Errorf("too many image builds or imports running, at most %d may run at the same time", maxConcurrentImageJobs)
+
+// imageJobFunc runs a background image operation on an uploaded file, reporting output
+// through onLine. The returned result is sent to clients as the final JSON message.
+type imageJobFunc func(ctx context.Context, input io.Reader, onLine func(string) error) (interface{}, error)
+
+// imageJob collects the output of a running image build or import so WebSocket clients
+// can follow it, including clients that connect after the job started
+type imageJob struct {
+	mu      sync.Mutex
+	output  outputBuffer
+	done    bool
+	result  interface{}
+	err     string
+	updated chan struct{} // Closed and replaced whenever the job changes
+}
+
+func newImageJob() *imageJob {
+	return &imageJob{updated: make(chan struct{})}
+}
+
+// notify wakes up waiting clients; the caller must hold the lock
+func (j *imageJob) notify() {
+	close(j.updated)
+	j.updated = make(chan struct{})
+}
+
+func (j *imageJob) appendLine(line string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.output.append(line)
+	j.notify()
+	return nil
+}
+
+func (j *imageJob) finish(result interface{}, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.done = true
+	j.result = result
+	if err != nil {
+		j.err = err.Error()
+	}
+	j.notify()
+}
+
+// since returns the kept lines after the first n, the number of lines dropped before them,
+// whether the job is done and a channel that is closed on the next change
+func (j *imageJob) since(n int) ([]string, int, bool, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	lines, skipped := j.output.since(n)
+	return lines, skipped, j.done, j.updated
+}
+
+// outputBuffer keeps the most recent lines of a job's output in a ring buffer
+type outputBuffer struct {
+	lines []string // At most maxImageJobOutputLines lines, the oldest at index start
+	start int
+	total int // Number of lines appended so far, including dropped ones
+}
+
+func (b *outputBuffer) append(line string) {
+	if len(b.lines) < maxImageJobOutputLines {
+		b.lines = append(b.lines, line)
+	} else {
+		b.lines[b.start] = line
+		b.start = (b.start + 1) % len(b.lines)
+	}
+	b.total++
+}
+
+// since returns the kept lines after the first n appended lines, and how many lines after
+// the first n were dropped before they could be returned
+func (b *outputBuffer) since(n int) ([]string, int) {
+	first := b.total - len(b.lines)
+	skipped := 0
+	if n < first {
+		skipped = first - n
+		n = first
+	}
+
+	lines := make([]string, 0, b.total-n)
+	for i := n - first; i < len(b.lines); i++ {
+		lines = append(lines, b.lines[(b.start+i)%len(b.lines)])
+	}
+	return lines, skipped
+}
+
+// imageJobRegistry tracks running and recently finished image jobs by ID
+type imageJobRegistry struct {
+	mu      sync.Mutex
+	jobs    map[string]*imageJob
+	running int // Jobs holding a slot, including those whose file is still being uploaded
+}
+
+func newImageJobRegistry() *imageJobRegistry {
+	return &imageJobRegistry{jobs: make(map[string]*imageJob)}
+}
+
+func (r *imageJobRegistry) add(id string, job *imageJob) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[id] = job
+}
+
+func (r *imageJobRegistry) get(id string) (*imageJob, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	return job, ok
+}
+
+func (r *imageJobRegistry) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.jobs, id)
+}
+
+// acquire claims a job slot, failing with errTooManyImageJobs if none is free. The slot is
+// freed by calling the returned function.
+func (r *imageJobRegistry) acquire() (func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running >= maxConcurrentImageJobs {
+		return nil, errTooManyImageJobs
+	}
+	r.running++
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.running--
+	}, nil
+}
+
+// startImageJob runs fn in the background on an uploaded file and returns the job ID.
+// The file is closed and removed, and the job slot is released, once the job finishes, or
+// immediately if it cannot start.
+func (h *DockerHandler) startImageJob(upload *os.File, release func(), fn imageJobFunc) (string, error) {
+	id, err := newImageJobID()
+	if err == nil {
+		_, err = upload.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		upload.Close()
+		os.Remove(upload.Name())
+		release()
+		return "", err
+	}
+
+	job := newImageJob()
+	h.imageJobs.add(id, job)
+
+	// The job outlives the upload request
+	go func() {
+		defer release()
+		defer os.Remove(upload.Name())
+		defer upload.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), imageJobTimeout)
+		defer cancel()
+
+		result, err := fn(ctx, upload, job.appendLine)
+		if err != nil {
+			log.Printf("Image job %s failed: %v", id, err)
+		}
+		job.finish(result, err)
+
+		time.AfterFunc(imageJobRetention, func() { h.imageJobs.remove(id) })
+	}()
+
+	return id, nil
+}
+
+// streamImageJob follows the job named by the "id" route variable over WebSocket.
+// Output lines are sent as text messages from the beginning of the job, with lines that are
+// no longer kept replaced by a single notice, followed by the job result or {"error": "<operation> failed: ..."}.
+func (h *DockerHandler) streamImageJob(w http.ResponseWriter, r *http.Request, operation string) {
+	vars := mux.Vars(r)
+	jobID := vars["id"]
+
+	job, ok := h.imageJobs.get(jobID)
+	if !ok {
+		respondWithError(w, http.StatusNotFound, operation+" not found")
+		return
+	}
+
+	// Upgrade HTTP connection to WebSocket
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade to WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Create context with cancel for cleanup
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// The job keeps running when the client disconnects
+	keepAliveWebSocket(ctx, cancel, conn)
+
+	sent := 0
+	for {
+		lines, skipped, done, updated := job.since(sent)
+		sent += skipped + len(lines)
+		if skipped > 0 {
+			lines = append([]string{fmt.Sprintf("[%d earlier lines omitted]", skipped)}, lines...)
+		}
+		for _, line := range lines {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(line)); err != nil {
+				return
+			}
+		}
+
+		if done {
+			job.mu.Lock()
+			result, jobErr := job.result, job.err
+			job.mu.Unlock()
+
+			if jobErr != "" {
+				conn.WriteJSON(map[string]string{"error": operation + " failed: " + jobErr})
+			} else {
+				conn.WriteJSON(result)
+			}
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-updated:
+		}
+	}
+}
+
+// newImageJobID generates a random job identifier
+func newImageJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
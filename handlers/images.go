@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/dev-zapi/docker-simple-panel/docker"
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// TagImageRequest represents an image tag request
+type TagImageRequest struct {
+	Repo string `json:"repo"`          // Repository name, e.g. registry.example.com/app
+	Tag  string `json:"tag,omitempty"` // Tag name, defaults to "latest"
+}
+
+// ListImages handles listing all Docker images
+func (h *DockerHandler) ListImages(w http.ResponseWriter, r *http.Request) {
+	images, err := h.manager.ListImages(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list images: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    images,
+	})
+}
+
+// GetImage handles getting detailed information about an image
+func (h *DockerHandler) GetImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	imageID := vars["id"]
+
+	if imageID == "" {
+		respondWithError(w, http.StatusBadRequest, "Image ID is required")
+		return
+	}
+
+	image, err := h.manager.InspectImage(r.Context(), imageID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Image not found: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    image,
+	})
+}
+
+// DeleteImage handles removing an image.
+// Query parameters "force" and "no_prune" map to docker rmi --force and --no-prune.
+func (h *DockerHandler) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	imageID := vars["id"]
+
+	if imageID == "" {
+		respondWithError(w, http.StatusBadRequest, "Image ID is required")
+		return
+	}
+
+	force, err := parseBoolQuery(r, "force", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid force parameter")
+		return
+	}
+
+	noPrune, err := parseBoolQuery(r, "no_prune", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid no_prune parameter")
+		return
+	}
+
+	results, err := h.manager.RemoveImage(r.Context(), imageID, force, noPrune)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if client.IsErrNotFound(err) {
+			status = http.StatusNotFound
+		}
+		respondWithError(w, status, "Failed to delete image: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Image deleted successfully",
+		Data:    results,
+	})
+}
+
+// TagImage handles adding a repository:tag reference to an image
+func (h *DockerHandler) TagImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	imageID := vars["id"]
+
+	if imageID == "" {
+		respondWithError(w, http.StatusBadRequest, "Image ID is required")
+		return
+	}
+
+	var req TagImageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Repo == "" {
+		respondWithError(w, http.StatusBadRequest, "Repository is required")
+		return
+	}
+	if req.Tag == "" {
+		req.Tag = "latest"
+	}
+
+	target := req.Repo + ":" + req.Tag
+	if err := h.manager.TagImage(r.Context(), imageID, target); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to tag image: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Image tagged as " + target,
+	})
+}
+
+// PruneImages handles removing unused images.
+// By default only dangling images are removed; all=true removes every image without containers.
+func (h *DockerHandler) PruneImages(w http.ResponseWriter, r *http.Request) {
+	all, err := parseBoolQuery(r, "all", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid all parameter")
+		return
+	}
+
+	result, err := h.manager.PruneImages(r.Context(), all)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to prune images: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: fmt.Sprintf("Removed %d image(s)", len(result.ImagesDeleted)),
+		Data:    result,
+	})
+}
+
+// imageUpdateCheckTimeout bounds how long an update check may keep the response open
+const imageUpdateCheckTimeout = 5 * time.Minute
+
+// CheckImageUpdates handles checking running containers for newer images in their registries.
+// Registry lookups are cached for an hour; refresh=true bypasses the cache.
+func (h *DockerHandler) CheckImageUpdates(w http.ResponseWriter, r *http.Request) {
+	refresh, err := parseBoolQuery(r, "refresh", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid refresh parameter")
+		return
+	}
+
+	extendWriteDeadline(w, imageUpdateCheckTimeout)
+
+	updates, err := h.manager.CheckImageUpdates(r.Context(), refresh)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to check image updates: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    updates,
+	})
+}
+
+// pullProgressInterval limits how often pull progress is sent to the client
+const pullProgressInterval = 250 * time.Millisecond
+
+// PullImage handles WebSocket connections for pulling an image with progress.
+// Query parameters: image (required), and optionally tag or digest.
+// Progress is sent as models.ImagePullProgress messages; the last one has done=true
+// and carries the manifest digest and image ID.
+func (h *DockerHandler) PullImage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	image := query.Get("image")
+	if image == "" {
+		respondWithError(w, http.StatusBadRequest, "Image is required")
+		return
+	}
+
+	ref, err := docker.ParseImageReference(image, query.Get("tag"), query.Get("digest"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid image: "+err.Error())
+		return
+	}
+
+	// Upgrade HTTP connection to WebSocket
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade to WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Create context with cancel for cleanup
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// A client disconnection cancels the pull
+	keepAliveWebSocket(ctx, cancel, conn)
+
+	var lastSent time.Time
+	result, err := h.manager.PullImage(ctx, ref, func(progress *models.ImagePullProgress) error {
+		if time.Since(lastSent) < pullProgressInterval {
+			return nil
+		}
+		lastSent = time.Now()
+		return conn.WriteJSON(progress)
+	})
+
+	if err != nil {
+		if ctx.Err() == nil {
+			conn.WriteJSON(map[string]string{
+				"error": "Failed to pull image: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	conn.WriteJSON(result)
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// ExportImage handles downloading an image as a tar archive (docker save).
+// The archive includes all layers and tags and can be imported with ImportImage or docker load.
+func (h *DockerHandler) ExportImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	imageID := vars["id"]
+
+	if imageID == "" {
+		respondWithError(w, http.StatusBadRequest, "Image ID is required")
+		return
+	}
+
+	image, err := h.manager.InspectImage(r.Context(), imageID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Image not found: "+err.Error())
+		return
+	}
+
+	archive, err := h.manager.SaveImage(r.Context(), imageID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to export image: "+err.Error())
+		return
+	}
+	defer archive.Close()
+
+	name := image.ID
+	if len(image.RepoTags) > 0 {
+		name = image.RepoTags[0]
+	}
+
+	// Large images take much longer to download than the server write timeout
+	extendWriteDeadline(w, 0)
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", archiveFileName(name)+".tar"))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, archive); err != nil {
+		log.Printf("Failed to stream image export for %s: %v", imageID, err)
+	}
+}
+
+// maxImageArchiveSize limits the size of an uploaded image archive
+const maxImageArchiveSize = 20 << 30
+
+// ImportImage handles uploading an image archive created by docker save (optionally
+// compressed) and starting its import. The body is the archive itself or a multipart form
+// with the archive as its first file. Progress is streamed by StreamImageImport.
+func (h *DockerHandler) ImportImage(w http.ResponseWriter, r *http.Request) {
+	// Claim a job slot before the upload, so a rejected import does not transfer its archive
+	release, err := h.imageJobs.acquire()
+	if err != nil {
+		respondWithError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+
+	// Uploading a large archive can take much longer than the server timeouts
+	extendReadDeadline(w, imageUploadTimeout)
+	extendWriteDeadline(w, imageUploadTimeout)
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageArchiveSize)
+
+	archive, err := os.CreateTemp("", "dsp-upload-*.tar")
+	if err != nil {
+		release()
+		respondWithError(w, http.StatusInternalServerError, "Failed to store image archive: "+err.Error())
+		return
+	}
+
+	if err := copyUploadedFile(r, archive); err != nil {
+		archive.Close()
+		os.Remove(archive.Name())
+		release()
+		respondWithError(w, http.StatusBadRequest, "Failed to read image archive: "+err.Error())
+		return
+	}
+
+	importID, err := h.startImageJob(archive, release, func(ctx context.Context, input io.Reader, onLine func(string) error) (interface{}, error) {
+		loaded, err := h.manager.LoadImage(ctx, input, onLine)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]string{"images": loaded}, nil
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to start import: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, models.Response{
+		Success: true,
+		Message: "Import started",
+		Data:    ImageImportStarted{ImportID: importID},
+	})
+}
+
+// ImageImportStarted is returned when an import has been accepted
+type ImageImportStarted struct {
+	ImportID string `json:"import_id"`
+}
+
+// StreamImageImport handles WebSocket connections for following an image import.
+// Progress lines are sent as text messages. The last message is {"images": [...]} with the
+// loaded references on success or {"error": "..."} on failure.
+func (h *DockerHandler) StreamImageImport(w http.ResponseWriter, r *http.Request) {
+	h.streamImageJob(w, r, "Import")
+}
+
+// copyUploadedFile copies the request body to dst. For multipart forms, the first file part is copied.
+func copyUploadedFile(r *http.Request, dst io.Writer) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		_, err := io.Copy(dst, r.Body)
+		return err
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return errors.New("no file uploaded")
+		}
+		if err != nil {
+			return err
+		}
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		_, err = io.Copy(dst, part)
+		part.Close()
+		return err
+	}
+}
+
+// archiveFileName turns an image reference into a safe download file name
+func archiveFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/docker/docker/client"
+	"github.com/gorilla/mux"
+
+	"github.com/dev-zapi/docker-simple-panel/docker"
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// CreateNetworkRequest represents a network creation request
+type CreateNetworkRequest struct {
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver,omitempty"` // Defaults to "bridge"
+	Internal   bool              `json:"internal,omitempty"`
+	Attachable bool              `json:"attachable,omitempty"`
+	EnableIPv6 bool              `json:"enable_ipv6,omitempty"`
+	Subnet     string            `json:"subnet,omitempty"` // CIDR, required for static container IPs
+	Gateway    string            `json:"gateway,omitempty"`
+	IPRange    string            `json:"ip_range,omitempty"`
+	Options    map[string]string `json:"options,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+// ConnectNetworkRequest represents a request to attach a container to a network
+type ConnectNetworkRequest struct {
+	Container   string   `json:"container"`
+	Aliases     []string `json:"aliases,omitempty"`
+	IPv4Address string   `json:"ipv4_address,omitempty"`
+	IPv6Address string   `json:"ipv6_address,omitempty"`
+}
+
+// DisconnectNetworkRequest represents a request to detach a container from a network
+type DisconnectNetworkRequest struct {
+	Container string `json:"container"`
+	Force     bool   `json:"force,omitempty"`
+}
+
+// networkErrorStatus maps a network operation error to an HTTP status code
+func networkErrorStatus(err error) int {
+	if client.IsErrNotFound(err) {
+		return http.StatusNotFound
+	}
+	return dockerErrorStatus(err)
+}
+
+// ListNetworks handles listing all networks
+func (h *DockerHandler) ListNetworks(w http.ResponseWriter, r *http.Request) {
+	networks, err := h.manager.ListNetworks(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list networks: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    networks,
+	})
+}
+
+// GetNetwork handles getting a single network
+func (h *DockerHandler) GetNetwork(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	networkID := vars["id"]
+
+	if networkID == "" {
+		respondWithError(w, http.StatusBadRequest, "Network ID is required")
+		return
+	}
+
+	network, err := h.manager.InspectNetwork(r.Context(), networkID)
+	if err != nil {
+		respondWithError(w, networkErrorStatus(err), "Failed to get network: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    network,
+	})
+}
+
+// CreateNetwork handles creating a network
+func (h *DockerHandler) CreateNetwork(w http.ResponseWriter, r *http.Request) {
+	var req CreateNetworkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Network name is required")
+		return
+	}
+	if req.Subnet == "" && (req.Gateway != "" || req.IPRange != "") {
+		respondWithError(w, http.StatusBadRequest, "Gateway and IP range require a subnet")
+		return
+	}
+	if err := validateNetworkAddresses(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid network settings: "+err.Error())
+		return
+	}
+
+	networkID, err := h.manager.CreateNetwork(r.Context(), docker.CreateNetworkOptions{
+		Name:       req.Name,
+		Driver:     req.Driver,
+		Internal:   req.Internal,
+		Attachable: req.Attachable,
+		EnableIPv6: req.EnableIPv6,
+		Subnet:     req.Subnet,
+		Gateway:    req.Gateway,
+		IPRange:    req.IPRange,
+		Options:    req.Options,
+		Labels:     req.Labels,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create network: "+err.Error())
+		return
+	}
+
+	network, err := h.manager.InspectNetwork(r.Context(), networkID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Network created but could not be inspected: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Network created successfully",
+		Data:    network,
+	})
+}
+
+// DeleteNetwork handles removing a network
+func (h *DockerHandler) DeleteNetwork(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	networkID := vars["id"]
+
+	if networkID == "" {
+		respondWithError(w, http.StatusBadRequest, "Network ID is required")
+		return
+	}
+
+	if err := h.manager.RemoveNetwork(r.Context(), networkID); err != nil {
+		respondWithError(w, networkErrorStatus(err), "Failed to delete network: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Network deleted successfully",
+	})
+}
+
+// PruneNetworks handles removing all networks not used by any container
+func (h *DockerHandler) PruneNetworks(w http.ResponseWriter, r *http.Request) {
+	deleted, err := h.manager.PruneNetworks(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to prune networks: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: fmt.Sprintf("Removed %d network(s)", len(deleted)),
+		Data:    deleted,
+	})
+}
+
+// ConnectNetwork handles attaching a container to a network with optional aliases and static IPs
+func (h *DockerHandler) ConnectNetwork(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	networkID := vars["id"]
+
+	if networkID == "" {
+		respondWithError(w, http.StatusBadRequest, "Network ID is required")
+		return
+	}
+
+	var req ConnectNetworkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Container == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+	if req.IPv4Address != "" {
+		if ip := net.ParseIP(req.IPv4Address); ip == nil || ip.To4() == nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid IPv4 address")
+			return
+		}
+	}
+	if req.IPv6Address != "" {
+		if ip := net.ParseIP(req.IPv6Address); ip == nil || ip.To4() != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid IPv6 address")
+			return
+		}
+	}
+
+	err := h.manager.ConnectNetwork(r.Context(), networkID, req.Container, docker.ConnectNetworkOptions{
+		Aliases:     req.Aliases,
+		IPv4Address: req.IPv4Address,
+		IPv6Address: req.IPv6Address,
+	})
+	if err != nil {
+		respondWithError(w, networkErrorStatus(err), "Failed to connect container: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Container connected successfully",
+	})
+}
+
+// DisconnectNetwork handles detaching a container from a network
+func (h *DockerHandler) DisconnectNetwork(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	networkID := vars["id"]
+
+	if networkID == "" {
+		respondWithError(w, http.StatusBadRequest, "Network ID is required")
+		return
+	}
+
+	var req DisconnectNetworkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Container == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	if err := h.manager.DisconnectNetwork(r.Context(), networkID, req.Container, req.Force); err != nil {
+		respondWithError(w, networkErrorStatus(err), "Failed to disconnect container: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Container disconnected successfully",
+	})
+}
+
+// validateNetworkAddresses checks the subnet, gateway and IP range of a network request
+func validateNetworkAddresses(req CreateNetworkRequest) error {
+	if req.Subnet == "" {
+		return nil
+	}
+
+	_, subnet, err := net.ParseCIDR(req.Subnet)
+	if err != nil {
+		return fmt.Errorf("invalid subnet %q", req.Subnet)
+	}
+	if req.Gateway != "" {
+		gateway := net.ParseIP(req.Gateway)
+		if gateway == nil || !subnet.Contains(gateway) {
+			return fmt.Errorf("gateway %q is not an address in %s", req.Gateway, req.Subnet)
+		}
+	}
+	if req.IPRange != "" {
+		ip, _, err := net.ParseCIDR(req.IPRange)
+		if err != nil || !subnet.Contains(ip) {
+			return fmt.Errorf("IP range %q is not a range in %s", req.IPRange, req.Subnet)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/dev-zapi/docker-simple-panel/config"
+	"github.com/dev-zapi/docker-simple-panel/docker"
+	"github.com/dev-zapi/docker-simple-panel/models"
+	"github.com/dev-zapi/docker-simple-panel/stacks"
+)
+
+// stackOperationTimeout bounds how long a deployment (including image pulls) may keep the response open
+const stackOperationTimeout = 30 * time.Minute
+
+// StackHandler handles compose stack management requests
+type StackHandler struct {
+	manager       *docker.Manager
+	configManager *config.Manager
+	mu            sync.Mutex // Serializes deployments and removals
+}
+
+// NewStackHandler creates a new StackHandler
+func NewStackHandler(manager *docker.Manager, configManager *config.Manager) *StackHandler {
+	return &StackHandler{
+		manager:       manager,
+		configManager: configManager,
+	}
+}
+
+// DeployStackRequest represents a stack deployment request
+type DeployStackRequest struct {
+	Content string `json:"content"` // Compose YAML document
+}
+
+// StackDetail represents a stored stack with its compose document
+type StackDetail struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// store returns the stack store for the configured stacks directory
+func (h *StackHandler) store() *stacks.Store {
+	return stacks.NewStore(h.configManager.GetStacksDir())
+}
+
+// hostDir returns the directory of a stack as seen by the Docker host. When the panel runs in a
+// container its own path means nothing to the host, so the directory is only known if
+// docker.stacks_host_dir is set; otherwise "" is returned.
+func (h *StackHandler) hostDir(name, workingDir string) string {
+	if hostDir := h.configManager.GetStacksHostDir(); hostDir != "" {
+		return filepath.Join(hostDir, name)
+	}
+	if h.manager.IsInContainer() {
+		return ""
+	}
+	return workingDir
+}
+
+// ListStacks handles listing stored stacks
+func (h *StackHandler) ListStacks(w http.ResponseWriter, r *http.Request) {
+	list, err := h.store().List()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list stacks: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    list,
+	})
+}
+
+// GetStack handles getting the compose document of a stack
+func (h *StackHandler) GetStack(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	if err := stacks.ValidateProjectName(name); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	content, err := h.store().Load(name)
+	if err != nil {
+		if errors.Is(err, stacks.ErrStackNotFound) {
+			respondWithError(w, http.StatusNotFound, "Stack not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to read stack: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data: StackDetail{
+			Name:    name,
+			Content: string(content),
+		},
+	})
+}
+
+// DeployStack handles creating or updating a stack from a compose document.
+// With dry_run=true the document is validated and the planned changes are returned without
+// saving the document or touching any Docker resources.
+func (h *StackHandler) DeployStack(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	if err := stacks.ValidateProjectName(name); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dryRun, err := parseBoolQuery(r, "dry_run", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid dry_run parameter")
+		return
+	}
+
+	var req DeployStackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	file, err := stacks.Parse([]byte(req.Content))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	store := h.store()
+	workingDir, err := store.Dir(name)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	extendWriteDeadline(w, stackOperationTimeout)
+
+	// The document is only stored once its plan has been accepted, so a rejected document
+	// does not replace the last good one
+	var saveErr error
+	plan, err := h.manager.DeployStack(r.Context(), name, file, h.hostDir(name, workingDir), h.configManager.GetStopTimeout(), dryRun, func() error {
+		saveErr = store.Save(name, []byte(req.Content))
+		return saveErr
+	})
+	if saveErr != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save stack: "+saveErr.Error())
+		return
+	}
+	if err != nil {
+		respondWithStackError(w, "Failed to deploy stack: ", plan, err)
+		return
+	}
+
+	message := "Stack deployed successfully"
+	if dryRun {
+		message = "Dry run completed, no changes were made"
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: message,
+		Data:    plan,
+	})
+}
+
+// RemoveStack handles removing a stack's containers and networks and its stored document.
+// Volumes are only removed with remove_volumes=true; dry_run=true reports what would be removed.
+func (h *StackHandler) RemoveStack(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	if err := stacks.ValidateProjectName(name); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dryRun, err := parseBoolQuery(r, "dry_run", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid dry_run parameter")
+		return
+	}
+
+	removeVolumes, err := parseBoolQuery(r, "remove_volumes", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid remove_volumes parameter")
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	extendWriteDeadline(w, stackOperationTimeout)
+
+	plan, err := h.manager.RemoveStack(r.Context(), name, removeVolumes, h.configManager.GetStopTimeout(), dryRun)
+	if err != nil {
+		respondWithStackError(w, "Failed to remove stack: ", plan, err)
+		return
+	}
+
+	if !dryRun {
+		if err := h.store().Delete(name); err != nil && !errors.Is(err, stacks.ErrStackNotFound) {
+			respondWithError(w, http.StatusInternalServerError, "Stack resources removed but failed to delete stack file: "+err.Error())
+			return
+		}
+	}
+
+	message := "Stack removed successfully"
+	if dryRun {
+		message = "Dry run completed, no changes were made"
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: message,
+		Data:    plan,
+	})
+}
+
+// respondWithStackError sends a stack operation error, including the partial plan when available
+func respondWithStackError(w http.ResponseWriter, prefix string, plan *models.StackPlan, err error) {
+	status := dockerErrorStatus(err)
+	if plan == nil && status != http.StatusForbidden {
+		// Planning failures are caused by the compose document or missing external resources
+		status = http.StatusBadRequest
+	}
+
+	respondWithJSON(w, status, models.Response{
+		Success: false,
+		Message: prefix + err.Error(),
+		Data:    plan,
+	})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// statsDeadlineMargin is added to the estimated sampling time of a stats snapshot
+const statsDeadlineMargin = 15 * time.Second
+
+// ListContainerStats handles getting a resource usage snapshot for all running containers
+func (h *DockerHandler) ListContainerStats(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := h.manager.ListContainerStats(r.Context(), func(estimate time.Duration) {
+		// Sampling many containers takes longer than the server write timeout
+		extendWriteDeadline(w, estimate+statsDeadlineMargin)
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to collect container stats: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    snapshots,
+	})
+}
+
+// GetContainerStats handles getting a single resource usage sample for a container
+func (h *DockerHandler) GetContainerStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	stats, err := h.manager.ContainerStats(r.Context(), containerID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get container stats: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    stats,
+	})
+}
+
+// StreamContainerStats handles WebSocket connections for streaming container resource usage.
+// Each sample is sent as a JSON-encoded models.ContainerStats message, roughly once per second.
+func (h *DockerHandler) StreamContainerStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	containerID := vars["id"]
+
+	if containerID == "" {
+		respondWithError(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	// Upgrade HTTP connection to WebSocket
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade to WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Create context with cancel for cleanup
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	keepAliveWebSocket(ctx, cancel, conn)
+
+	err = h.manager.StreamContainerStats(ctx, containerID, func(stats *models.ContainerStats) error {
+		return conn.WriteJSON(stats)
+	})
+	if err != nil && ctx.Err() == nil {
+		conn.WriteJSON(map[string]string{
+			"error": "Failed to stream container stats: " + err.Error(),
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/dev-zapi/docker-simple-panel/docker"
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+// GetTopology handles getting the graph of containers and networks.
+// With format=dot the graph is returned as Graphviz DOT instead of JSON.
+func (h *DockerHandler) GetTopology(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "dot" {
+		respondWithError(w, http.StatusBadRequest, "Invalid format: expected json or dot")
+		return
+	}
+
+	topology, err := h.manager.Topology(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to build topology: "+err.Error())
+		return
+	}
+
+	if format == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="topology.dot"`)
+		w.WriteHeader(http.StatusOK)
+		writeTopologyDOT(w, topology)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Data:    topology,
+	})
+}
+
+// writeTopologyDOT renders the topology as an undirected Graphviz graph. Containers are boxes
+// labeled with their published ports, networks are ellipses, and edges are labeled with the
+// container's addresses and aliases on the network.
+func writeTopologyDOT(w io.Writer, topology *models.Topology) {
+	fmt.Fprintln(w, "graph topology {")
+	fmt.Fprintln(w, "\trankdir=LR;")
+	fmt.Fprintln(w, "\tnode [fontname=\"Helvetica\"];")
+	fmt.Fprintln(w, "\tedge [fontname=\"Helvetica\", fontsize=10];")
+
+	for _, node := range topology.Nodes {
+		lines := []string{node.Name}
+		attrs := ""
+		switch node.Type {
+		case docker.TopologyContainerNode:
+			lines = append(lines, node.Image)
+			for _, port := range node.Ports {
+				host := port.HostPort
+				if port.HostIP != "" && port.HostIP != "0.0.0.0" && port.HostIP != "::" {
+					host = port.HostIP + ":" + host
+				}
+				lines = append(lines, host+" -> "+port.ContainerPort)
+			}
+			attrs = "shape=box"
+			if node.State != "running" {
+				attrs += ", style=dashed"
+			}
+		case docker.TopologyNetworkNode:
+			lines = append(lines, node.Driver)
+			lines = append(lines, node.Subnets...)
+			attrs = "shape=ellipse"
+			if node.Internal {
+				attrs += ", style=dashed"
+			}
+		}
+		fmt.Fprintf(w, "\t%s [label=%s, %s];\n", dotQuote(node.ID), dotLabel(lines), attrs)
+	}
+
+	for _, edge := range topology.Edges {
+		lines := []string{}
+		if edge.IPv4Address != "" {
+			lines = append(lines, edge.IPv4Address)
+		}
+		if edge.IPv6Address != "" {
+			lines = append(lines, edge.IPv6Address)
+		}
+		if len(edge.Aliases) > 0 {
+			lines = append(lines, strings.Join(edge.Aliases, ", "))
+		}
+		fmt.Fprintf(w, "\t%s -- %s [label=%s];\n", dotQuote(edge.Source), dotQuote(edge.Target), dotLabel(lines))
+	}
+
+	fmt.Fprintln(w, "}")
+}
+
+// dotLabel joins label lines with DOT line breaks
+func dotLabel(lines []string) string {
+	escaped := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line != "" {
+			escaped = append(escaped, dotEscape(line))
+		}
+	}
+	return `"` + strings.Join(escaped, `\n`) + `"`
+}
+
+// dotQuote quotes a DOT identifier
+func dotQuote(s string) string {
+	return `"` + dotEscape(s) + `"`
+}
+
+func dotEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
@@ -0,0 +1,591 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/dev-zapi/docker-simple-panel/docker"
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+const (
+	// maxVolumeFileEditSize limits the content of a file saved from the editor
+	maxVolumeFileEditSize = 10 << 20
+	// maxVolumeUploadSize limits the total size of files uploaded into a volume at once
+	maxVolumeUploadSize = 2 << 30
+)
+
+// errRangeNotSatisfiable is returned for byte ranges outside of a file
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// WriteVolumeFileRequest represents a request to save a file in a volume. To replace an existing
+// file, pass the size and modification time it was read at, or set overwrite.
+type WriteVolumeFileRequest struct {
+	Content         string `json:"content"`
+	ExpectedSize    *int64 `json:"expected_size,omitempty"`
+	ExpectedModTime string `json:"expected_mod_time,omitempty"`
+	Overwrite       bool   `json:"overwrite,omitempty"`
+}
+
+// MoveVolumeFileRequest represents a request to rename or move a file or directory in a volume
+type MoveVolumeFileRequest struct {
+	From            string `json:"from"`
+	To              string `json:"to"`
+	ExpectedSize    *int64 `json:"expected_size,omitempty"`
+	ExpectedModTime string `json:"expected_mod_time,omitempty"`
+}
+
+// WriteVolumeFile handles saving the content of a file in a volume
+func (h *DockerHandler) WriteVolumeFile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	volumeName := vars["name"]
+
+	if volumeName == "" {
+		respondWithError(w, http.StatusBadRequest, "Volume name is required")
+		return
+	}
+
+	filePath, ok := volumeWritePath(w, r.URL.Query().Get("path"))
+	if !ok {
+		return
+	}
+
+	var req WriteVolumeFileRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxVolumeFileEditSize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	expected, err := parseFileVersion(req.ExpectedSize, req.ExpectedModTime)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid file version: "+err.Error())
+		return
+	}
+
+	explorerImage := h.configManager.GetVolumeExplorerImage()
+	info, err := h.manager.WriteVolumeFile(r.Context(), volumeName, filePath, []byte(req.Content), expected, req.Overwrite, explorerImage)
+	if err != nil {
+		respondWithError(w, volumeFileErrorStatus(err), "Failed to write file: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "File saved successfully",
+		Data:    info,
+	})
+}
+
+// UploadVolumeFiles handles uploading files into a volume directory as a multipart form.
+// Each file part is written to the directory given by "path" under its base name; existing
+// files are only replaced with overwrite=true.
+func (h *DockerHandler) UploadVolumeFiles(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	volumeName := vars["name"]
+
+	if volumeName == "" {
+		respondWithError(w, http.StatusBadRequest, "Volume name is required")
+		return
+	}
+
+	dir := r.URL.Query().Get("path")
+	if dir == "" {
+		dir = "/"
+	}
+	if !isValidPath(dir) {
+		respondWithError(w, http.StatusBadRequest, "Invalid path")
+		return
+	}
+	dir = path.Clean(dir)
+
+	overwrite, err := parseBoolQuery(r, "overwrite", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid overwrite parameter")
+		return
+	}
+
+	// Uploading large files can take much longer than the server timeouts
+	extendReadDeadline(w, volumeUploadTimeout)
+	extendWriteDeadline(w, volumeUploadTimeout)
+	r.Body = http.MaxBytesReader(w, r.Body, maxVolumeUploadSize)
+
+	// Files are stored first so nothing is written to the volume if the upload fails
+	tempDir, err := os.MkdirTemp("", "dsp-volume-upload-*")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to store upload: "+err.Error())
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	files, err := spoolUploadedFiles(r, tempDir)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Failed to read upload: "+err.Error())
+		return
+	}
+	defer func() {
+		for _, file := range files {
+			file.Content.(*os.File).Close()
+		}
+	}()
+
+	explorerImage := h.configManager.GetVolumeExplorerImage()
+	written, err := h.manager.UploadVolumeFiles(r.Context(), volumeName, dir, files, overwrite, explorerImage)
+	if err != nil {
+		respondWithError(w, volumeFileErrorStatus(err), "Failed to upload files: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: fmt.Sprintf("Uploaded %d file(s)", len(written)),
+		Data:    written,
+	})
+}
+
+// CreateVolumeDirectory handles creating a directory in a volume
+func (h *DockerHandler) CreateVolumeDirectory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	volumeName := vars["name"]
+
+	if volumeName == "" {
+		respondWithError(w, http.StatusBadRequest, "Volume name is required")
+		return
+	}
+
+	dirPath, ok := volumeWritePath(w, r.URL.Query().Get("path"))
+	if !ok {
+		return
+	}
+
+	explorerImage := h.configManager.GetVolumeExplorerImage()
+	info, err := h.manager.CreateVolumeDirectory(r.Context(), volumeName, dirPath, explorerImage)
+	if err != nil {
+		respondWithError(w, volumeFileErrorStatus(err), "Failed to create directory: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Directory created successfully",
+		Data:    info,
+	})
+}
+
+// MoveVolumeFile handles renaming or moving a file or directory within a volume
+func (h *DockerHandler) MoveVolumeFile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	volumeName := vars["name"]
+
+	if volumeName == "" {
+		respondWithError(w, http.StatusBadRequest, "Volume name is required")
+		return
+	}
+
+	var req MoveVolumeFileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	from, ok := volumeWritePath(w, req.From)
+	if !ok {
+		return
+	}
+	to, ok := volumeWritePath(w, req.To)
+	if !ok {
+		return
+	}
+
+	expected, err := parseFileVersion(req.ExpectedSize, req.ExpectedModTime)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid file version: "+err.Error())
+		return
+	}
+
+	explorerImage := h.configManager.GetVolumeExplorerImage()
+	info, err := h.manager.MoveVolumeFile(r.Context(), volumeName, from, to, expected, explorerImage)
+	if err != nil {
+		respondWithError(w, volumeFileErrorStatus(err), "Failed to move file: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "File moved successfully",
+		Data:    info,
+	})
+}
+
+// DeleteVolumeFile handles deleting a file or directory in a volume. Non-empty directories
+// require recursive=true; expected_size and expected_mod_time guard against concurrent changes.
+func (h *DockerHandler) DeleteVolumeFile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	volumeName := vars["name"]
+
+	if volumeName == "" {
+		respondWithError(w, http.StatusBadRequest, "Volume name is required")
+		return
+	}
+
+	query := r.URL.Query()
+	filePath, ok := volumeWritePath(w, query.Get("path"))
+	if !ok {
+		return
+	}
+
+	recursive, err := parseBoolQuery(r, "recursive", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid recursive parameter")
+		return
+	}
+
+	var expectedSize *int64
+	if value := query.Get("expected_size"); value != "" {
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid expected_size parameter")
+			return
+		}
+		expectedSize = &size
+	}
+	expected, err := parseFileVersion(expectedSize, query.Get("expected_mod_time"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid file version: "+err.Error())
+		return
+	}
+
+	explorerImage := h.configManager.GetVolumeExplorerImage()
+	if err := h.manager.DeleteVolumeFile(r.Context(), volumeName, filePath, recursive, expected, explorerImage); err != nil {
+		respondWithError(w, volumeFileErrorStatus(err), "Failed to delete file: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "File deleted successfully",
+	})
+}
+
+// DownloadVolumeFile handles streaming a file of a volume unchanged. A single byte range can be
+// requested with the Range header, e.g. to resume a download.
+func (h *DockerHandler) DownloadVolumeFile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	volumeName := vars["name"]
+
+	if volumeName == "" {
+		respondWithError(w, http.StatusBadRequest, "Volume name is required")
+		return
+	}
+
+	filePath := r.URL.Query().Get("path")
+	if filePath == "" {
+		respondWithError(w, http.StatusBadRequest, "File path is required")
+		return
+	}
+	if !isValidPath(filePath) {
+		respondWithError(w, http.StatusBadRequest, "Invalid path")
+		return
+	}
+	filePath = path.Clean(filePath)
+
+	explorerImage := h.configManager.GetVolumeExplorerImage()
+	file, err := h.manager.OpenVolumeFile(r.Context(), volumeName, filePath, explorerImage)
+	if err != nil {
+		respondWithError(w, volumeFileErrorStatus(err), "Failed to download file: "+err.Error())
+		return
+	}
+	defer file.Close()
+
+	serveFileStream(w, r, file, fmt.Sprintf("file %s of volume %s", filePath, volumeName))
+}
+
+// DownloadVolumeDirectory handles streaming a directory of a volume as a tar (default) or zip
+// archive, selected with the "format" parameter
+func (h *DockerHandler) DownloadVolumeDirectory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	volumeName := vars["name"]
+
+	if volumeName == "" {
+		respondWithError(w, http.StatusBadRequest, "Volume name is required")
+		return
+	}
+
+	query := r.URL.Query()
+	dirPath := query.Get("path")
+	if dirPath == "" {
+		dirPath = "/"
+	}
+	if !isValidPath(dirPath) {
+		respondWithError(w, http.StatusBadRequest, "Invalid path")
+		return
+	}
+	dirPath = path.Clean(dirPath)
+
+	format := query.Get("format")
+	if format == "" {
+		format = docker.ArchiveFormatTar
+	}
+	contentType := ""
+	switch format {
+	case docker.ArchiveFormatTar:
+		contentType = "application/x-tar"
+	case docker.ArchiveFormatZip:
+		contentType = "application/zip"
+	default:
+		respondWithError(w, http.StatusBadRequest, "Invalid format parameter, must be tar or zip")
+		return
+	}
+
+	explorerImage := h.configManager.GetVolumeExplorerImage()
+	archive, err := h.manager.ArchiveVolumeDirectory(r.Context(), volumeName, dirPath, format, explorerImage)
+	if err != nil {
+		respondWithError(w, volumeFileErrorStatus(err), "Failed to download directory: "+err.Error())
+		return
+	}
+	defer archive.Close()
+
+	// Large directories take much longer to download than the server write timeout
+	extendWriteDeadline(w, 0)
+
+	name := path.Base(dirPath)
+	if dirPath == "/" {
+		name = volumeName
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", attachmentDisposition(name+"."+format))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, archive); err != nil {
+		log.Printf("Failed to stream directory %s of volume %s: %v", dirPath, volumeName, err)
+	}
+}
+
+// serveFileStream writes an opened file as a download. A single byte range can be requested
+// with the Range header; description names the file in logs.
+func serveFileStream(w http.ResponseWriter, r *http.Request, file *docker.FileStream, description string) {
+	lastModified := file.ModTime.UTC().Format(http.TimeFormat)
+	start, length, partial, err := parseByteRange(r.Header.Get("Range"), file.Size)
+	if ifRange := r.Header.Get("If-Range"); ifRange != "" && ifRange != lastModified {
+		// The file changed since the first part was downloaded
+		start, length, partial, err = 0, file.Size, false, nil
+	}
+	if err != nil {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", file.Size))
+		respondWithError(w, http.StatusRequestedRangeNotSatisfiable, "Failed to download file: "+err.Error())
+		return
+	}
+
+	reader := bufio.NewReader(file)
+	contentType := mime.TypeByExtension(path.Ext(file.Name))
+	if contentType == "" {
+		head, _ := reader.Peek(512)
+		contentType = http.DetectContentType(head)
+	}
+
+	// Large files take much longer to download than the server write timeout
+	extendWriteDeadline(w, 0)
+
+	w.Header().Set("Content-Type", contentType)
+	// Always download, so that files from the volume are never rendered by the browser
+	w.Header().Set("Content-Disposition", attachmentDisposition(file.Name))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Last-Modified", lastModified)
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+
+	status := http.StatusOK
+	if partial {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, file.Size))
+		status = http.StatusPartialContent
+	}
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := io.CopyN(io.Discard, reader, start); err != nil {
+		log.Printf("Failed to stream %s: %v", description, err)
+		return
+	}
+	if _, err := io.CopyN(w, reader, length); err != nil {
+		log.Printf("Failed to stream %s: %v", description, err)
+	}
+}
+
+// attachmentDisposition builds a Content-Disposition header for downloading a file. Names
+// that cannot be sent as they are fall back to a sanitized version.
+func attachmentDisposition(name string) string {
+	if disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name}); disposition != "" {
+		return disposition
+	}
+	return fmt.Sprintf("attachment; filename=%q", archiveFileName(name))
+}
+
+// parseByteRange parses a Range header for a file of the given size. Only a single byte range
+// is supported: without a header, with an invalid one or with several ranges, partial is false
+// and the whole file is selected.
+func parseByteRange(header string, size int64) (start, length int64, partial bool, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, size, false, nil
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, size, false, nil
+	}
+
+	if first == "" {
+		// A suffix range selects the last bytes of the file
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, size, false, nil
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, errRangeNotSatisfiable
+		}
+		n = min(n, size)
+		return size - n, n, true, nil
+	}
+
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, size, false, nil
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, size, false, nil
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return 0, 0, false, errRangeNotSatisfiable
+	}
+	return start, end - start + 1, true, nil
+}
+
+// volumeWritePath validates the target path of a write operation, which cannot be the volume
+// root, and writes a 400 response if it is invalid
+func volumeWritePath(w http.ResponseWriter, p string) (string, bool) {
+	if p == "" {
+		respondWithError(w, http.StatusBadRequest, "File path is required")
+		return "", false
+	}
+
+	// Validate path to prevent directory traversal attacks
+	if !isValidPath(p) {
+		respondWithError(w, http.StatusBadRequest, "Invalid path")
+		return "", false
+	}
+
+	p = path.Clean(p)
+	if p == "/" {
+		respondWithError(w, http.StatusBadRequest, "The volume root cannot be modified")
+		return "", false
+	}
+	return p, true
+}
+
+// parseFileVersion builds the expected file version from its size and modification time,
+// which must be given together
+func parseFileVersion(size *int64, modTime string) (*docker.FileVersion, error) {
+	if size == nil && modTime == "" {
+		return nil, nil
+	}
+	if size == nil || modTime == "" {
+		return nil, errors.New("expected_size and expected_mod_time must be given together")
+	}
+
+	t, err := docker.ParseFileTime(modTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expected_mod_time %q", modTime)
+	}
+	return &docker.FileVersion{Size: *size, ModTime: t}, nil
+}
+
+// volumeFileErrorStatus maps a volume file operation error to an HTTP status code
+func volumeFileErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, docker.ErrFileExists), errors.Is(err, docker.ErrFileChanged):
+		return http.StatusConflict
+	case errors.Is(err, docker.ErrNotRegularFile), errors.Is(err, docker.ErrNotDirectory):
+		return http.StatusBadRequest
+	}
+	return volumeErrorStatus(err)
+}
+
+// spoolUploadedFiles stores the file parts of a multipart form in dir. The returned uploads
+// read from the stored files, which the caller must close.
+func spoolUploadedFiles(r *http.Request, dir string) ([]docker.FileUpload, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	files := []docker.FileUpload{}
+	seen := make(map[string]bool)
+	closeAll := func() {
+		for _, file := range files {
+			file.Content.(*os.File).Close()
+		}
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+
+		name := part.FileName()
+		if name == "" {
+			part.Close()
+			continue
+		}
+		if name == "." || name == ".." || strings.ContainsAny(name, `/\`) || seen[name] {
+			part.Close()
+			closeAll()
+			return nil, fmt.Errorf("invalid or duplicate file name %q", name)
+		}
+		seen[name] = true
+
+		file, err := os.CreateTemp(dir, "upload-*")
+		if err != nil {
+			part.Close()
+			closeAll()
+			return nil, err
+		}
+		size, err := io.Copy(file, part)
+		part.Close()
+		if err == nil {
+			_, err = file.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			file.Close()
+			closeAll()
+			return nil, err
+		}
+		files = append(files, docker.FileUpload{Name: name, Size: size, Content: file})
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no files uploaded")
+	}
+	return files, nil
+}
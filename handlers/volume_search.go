@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/dev-zapi/docker-simple-panel/docker"
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+const (
+	// defaultVolumeSearchResults is the number of matches a volume search stops at by default
+	defaultVolumeSearchResults = 100
+	// maxVolumeSearchResults limits the max_results of a volume search
+	maxVolumeSearchResults = 10000
+	// defaultVolumeSearchFileSize is the size above which files are not searched for content by default
+	defaultVolumeSearchFileSize = 10 << 20
+)
+
+// SearchVolume handles WebSocket connections searching a volume. Files are matched by a name
+// glob (name), by their content (query, literal text or an extended regular expression with
+// regex=true), or both, below path. Each match is sent as a JSON-encoded
+// models.VolumeSearchMatch message as soon as it is found. The last message is a
+// models.VolumeSearchResult, or {"error": "..."} if the search failed.
+func (h *DockerHandler) SearchVolume(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	volumeName := vars["name"]
+
+	if volumeName == "" {
+		respondWithError(w, http.StatusBadRequest, "Volume name is required")
+		return
+	}
+
+	opts, err := parseVolumeSearchOptions(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid search: "+err.Error())
+		return
+	}
+
+	// Get the volume explorer image from config
+	explorerImage := h.configManager.GetVolumeExplorerImage()
+
+	// Upgrade HTTP connection to WebSocket
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade to WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Create context with cancel for cleanup
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	keepAliveWebSocket(ctx, cancel, conn)
+
+	result, err := h.manager.SearchVolume(ctx, volumeName, opts, explorerImage, func(match models.VolumeSearchMatch) error {
+		return conn.WriteJSON(match)
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			conn.WriteJSON(map[string]string{
+				"error": "Failed to search volume: " + err.Error(),
+			})
+		}
+		return
+	}
+	conn.WriteJSON(result)
+}
+
+// parseVolumeSearchOptions reads the path, name, query, regex, max_results and max_file_size
+// query parameters
+func parseVolumeSearchOptions(r *http.Request) (docker.VolumeSearchOptions, error) {
+	query := r.URL.Query()
+	opts := docker.VolumeSearchOptions{
+		Path:        query.Get("path"),
+		Name:        query.Get("name"),
+		Query:       query.Get("query"),
+		MaxResults:  defaultVolumeSearchResults,
+		MaxFileSize: defaultVolumeSearchFileSize,
+	}
+
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	// Validate path to prevent directory traversal attacks
+	if !isValidPath(opts.Path) {
+		return opts, errors.New("invalid path")
+	}
+	opts.Path = path.Clean(opts.Path)
+
+	if opts.Name == "" && opts.Query == "" {
+		return opts, errors.New("name or query is required")
+	}
+	if opts.Name != "" {
+		// Names are matched without their directory
+		if _, err := path.Match(opts.Name, ""); err != nil || strings.Contains(opts.Name, "/") {
+			return opts, errors.New("name must be a glob pattern for file names, e.g. *.yml")
+		}
+	}
+	// Several lines would be searched as separate patterns
+	if strings.ContainsAny(opts.Query, "\r\n") {
+		return opts, errors.New("query must be a single line")
+	}
+
+	var err error
+	if opts.Regex, err = parseBoolQuery(r, "regex", false); err != nil {
+		return opts, errors.New("regex must be true or false")
+	}
+
+	if value := query.Get("max_results"); value != "" {
+		opts.MaxResults, err = strconv.Atoi(value)
+		if err != nil || opts.MaxResults < 1 || opts.MaxResults > maxVolumeSearchResults {
+			return opts, errors.New("max_results must be between 1 and " + strconv.Itoa(maxVolumeSearchResults))
+		}
+	}
+	if value := query.Get("max_file_size"); value != "" {
+		opts.MaxFileSize, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || opts.MaxFileSize < 1 {
+			return opts, errors.New("max_file_size must be a positive number of bytes")
+		}
+	}
+	return opts, nil
+}
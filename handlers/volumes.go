@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/gorilla/mux"
+
+	"github.com/dev-zapi/docker-simple-panel/docker"
+	"github.com/dev-zapi/docker-simple-panel/models"
+)
+
+const (
+	// volumeUploadTimeout bounds how long uploading a volume archive may take
+	volumeUploadTimeout = 30 * time.Minute
+	// volumeRestoreTimeout bounds how long extracting an uploaded archive may take
+	volumeRestoreTimeout = time.Hour
+	// maxVolumeArchiveSize limits the size of an uploaded volume archive
+	maxVolumeArchiveSize = 20 << 30
+)
+
+// volumeErrorStatus maps a volume operation error to an HTTP status code
+func volumeErrorStatus(err error) int {
+	switch {
+	case client.IsErrNotFound(err):
+		return http.StatusNotFound
+	case errors.Is(err, docker.ErrVolumeNotEmpty), errors.Is(err, docker.ErrVolumeInUse), errors.Is(err, docker.ErrVolumeBusy):
+		return http.StatusConflict
+	case errors.Is(err, docker.ErrInvalidArchive):
+		return http.StatusBadRequest
+	}
+	return dockerErrorStatus(err)
+}
+
+// BackupVolume handles downloading the contents of a volume as a gzip-compressed tar archive
+func (h *DockerHandler) BackupVolume(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	volumeName := vars["name"]
+
+	if volumeName == "" {
+		respondWithError(w, http.StatusBadRequest, "Volume name is required")
+		return
+	}
+
+	// Get the volume explorer image from config
+	explorerImage := h.configManager.GetVolumeExplorerImage()
+
+	archive, err := h.manager.BackupVolume(r.Context(), volumeName, explorerImage)
+	if err != nil {
+		respondWithError(w, volumeErrorStatus(err), "Failed to back up volume: "+err.Error())
+		return
+	}
+	defer archive.Close()
+
+	// Large volumes take much longer to download than the server write timeout
+	extendWriteDeadline(w, 0)
+
+	fileName := fmt.Sprintf("%s-%s.tar.gz", archiveFileName(volumeName), time.Now().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, archive); err != nil {
+		log.Printf("Failed to stream backup of volume %s: %v", volumeName, err)
+	}
+}
+
+// RestoreVolume handles extracting an uploaded tar archive (optionally compressed) into a volume.
+// The body is the archive itself or a multipart form with the archive as its first file.
+// The volume must be empty unless clear=true; running containers using it are only stopped,
+// and started again afterwards, with stop_containers=true.
+func (h *DockerHandler) RestoreVolume(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	volumeName := vars["name"]
+
+	if volumeName == "" {
+		respondWithError(w, http.StatusBadRequest, "Volume name is required")
+		return
+	}
+
+	clearVolume, err := parseBoolQuery(r, "clear", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid clear parameter")
+		return
+	}
+	stopContainers, err := parseBoolQuery(r, "stop_containers", false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid stop_containers parameter")
+		return
+	}
+
+	// Uploading and extracting a large archive can take much longer than the server timeouts
+	extendReadDeadline(w, volumeUploadTimeout)
+	extendWriteDeadline(w, volumeUploadTimeout+volumeRestoreTimeout)
+	r.Body = http.MaxBytesReader(w, r.Body, maxVolumeArchiveSize)
+
+	// The archive is stored first so containers are not stopped while it is still uploading
+	archive, err := os.CreateTemp("", "dsp-upload-*.tar")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to store volume archive: "+err.Error())
+		return
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err := copyUploadedFile(r, archive); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Failed to read volume archive: "+err.Error())
+		return
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to read volume archive: "+err.Error())
+		return
+	}
+
+	// A half-extracted volume is worse than a finished one, so the restore outlives the request
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), volumeRestoreTimeout)
+	defer cancel()
+
+	result, err := h.manager.RestoreVolume(ctx, volumeName, h.configManager.GetVolumeExplorerImage(), archive, docker.RestoreVolumeOptions{
+		Clear:              clearVolume,
+		StopContainers:     stopContainers,
+		DefaultStopTimeout: h.configManager.GetStopTimeout(),
+	})
+	if err != nil {
+		respondWithJSON(w, volumeErrorStatus(err), models.Response{
+			Success: false,
+			Message: "Failed to restore volume: " + err.Error(),
+			Data:    result,
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.Response{
+		Success: true,
+		Message: "Volume restored successfully",
+		Data:    result,
+	})
+}
@@ -12,10 +12,12 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/dev-zapi/docker-simple-panel/backups"
 	"github.com/dev-zapi/docker-simple-panel/config"
 	"github.com/dev-zapi/docker-simple-panel/docker"
 	"github.com/dev-zapi/docker-simple-panel/handlers"
 	"github.com/dev-zapi/docker-simple-panel/middleware"
+	"github.com/dev-zapi/docker-simple-panel/registry"
 )
 
 func main() {
@@ -48,6 +50,24 @@ func main() {
 		log.Println("Docker daemon is accessible")
 	}
 
+	// Registry credentials are used by image update checks
+	registryHosts := make(map[string]registry.Host)
+	for _, reg := range configManager.GetRegistries() {
+		registryHosts[reg.Host] = registry.Host{
+			Username: reg.Username,
+			Password: reg.Password,
+			Insecure: reg.Insecure,
+		}
+	}
+	dockerManager.SetRegistryHosts(registryHosts)
+
+	// Scheduled volume backups
+	backupScheduler := backups.NewScheduler(dockerManager, configManager)
+	if err := backupScheduler.Start(); err != nil {
+		log.Printf("Warning: scheduled backups disabled: %v", err)
+	}
+	defer backupScheduler.Stop()
+
 	// Set Docker socket change callback
 	configManager.SetDockerSocketChangeCallback(func(newSocket string) error {
 		return dockerManager.RestartWithSocket(newSocket)
@@ -57,6 +77,8 @@ func main() {
 	authHandler := handlers.NewAuthHandler(configManager, cfg.Server.JWTSecret)
 	dockerHandler := handlers.NewDockerHandler(dockerManager, configManager)
 	configHandler := handlers.NewConfigHandler(configManager)
+	stackHandler := handlers.NewStackHandler(dockerManager, configManager)
+	backupHandler := handlers.NewBackupHandler(backupScheduler)
 
 	// Setup router
 	router := mux.NewRouter()
@@ -98,18 +120,89 @@ func main() {
 
 	// Docker container routes
 	protected.HandleFunc("/containers", dockerHandler.ListContainers).Methods("GET")
+	// Registered before /containers/{id} so "stats" is not matched as a container ID
+	protected.HandleFunc("/containers/stats", dockerHandler.ListContainerStats).Methods("GET")
 	protected.HandleFunc("/containers/{id}", dockerHandler.GetContainer).Methods("GET")
 	protected.HandleFunc("/containers/{id}/start", dockerHandler.StartContainer).Methods("POST")
 	protected.HandleFunc("/containers/{id}/stop", dockerHandler.StopContainer).Methods("POST")
 	protected.HandleFunc("/containers/{id}/restart", dockerHandler.RestartContainer).Methods("POST")
+	protected.HandleFunc("/containers/{id}/pause", dockerHandler.PauseContainer).Methods("POST")
+	protected.HandleFunc("/containers/{id}/unpause", dockerHandler.UnpauseContainer).Methods("POST")
+	protected.HandleFunc("/containers/{id}/kill", dockerHandler.KillContainer).Methods("POST")
+	protected.HandleFunc("/containers/{id}", dockerHandler.RemoveContainer).Methods("DELETE")
+	protected.HandleFunc("/containers/{id}/recreate", dockerHandler.RecreateContainer).Methods("POST")
 	protected.HandleFunc("/containers/{id}/logs/stream", dockerHandler.StreamContainerLogs).Methods("GET")
+	protected.HandleFunc("/containers/{id}/exec", dockerHandler.ExecContainer).Methods("GET")
+	protected.HandleFunc("/containers/{id}/stats", dockerHandler.GetContainerStats).Methods("GET")
+	protected.HandleFunc("/containers/{id}/stats/stream", dockerHandler.StreamContainerStats).Methods("GET")
+	protected.HandleFunc("/containers/{id}/files", dockerHandler.ListContainerFiles).Methods("GET")
+	protected.HandleFunc("/containers/{id}/file", dockerHandler.DownloadContainerFile).Methods("GET", "HEAD")
+	protected.HandleFunc("/containers/{id}/changes", dockerHandler.GetContainerChanges).Methods("GET")
+	protected.HandleFunc("/containers/{id}/upload", dockerHandler.UploadContainerFiles).Methods("POST")
 	protected.HandleFunc("/docker/health", dockerHandler.HealthCheck).Methods("GET")
 
+	// Docker Compose project routes
+	protected.HandleFunc("/compose/projects", dockerHandler.ListComposeProjects).Methods("GET")
+	protected.HandleFunc("/compose/projects/{name}", dockerHandler.GetComposeProject).Methods("GET")
+	protected.HandleFunc("/compose/projects/{name}/{action:start|stop|restart}", dockerHandler.ComposeProjectAction).Methods("POST")
+
+	// Stack routes
+	protected.HandleFunc("/stacks", stackHandler.ListStacks).Methods("GET")
+	protected.HandleFunc("/stacks/{name}", stackHandler.GetStack).Methods("GET")
+	protected.HandleFunc("/stacks/{name}", stackHandler.DeployStack).Methods("PUT")
+	protected.HandleFunc("/stacks/{name}", stackHandler.RemoveStack).Methods("DELETE")
+
+	// Docker image routes
+	protected.HandleFunc("/images", dockerHandler.ListImages).Methods("GET")
+	protected.HandleFunc("/images/prune", dockerHandler.PruneImages).Methods("POST")
+	// Registered before /images/{id} so "pull" and "updates" are not treated as image IDs
+	protected.HandleFunc("/images/pull", dockerHandler.PullImage).Methods("GET")
+	protected.HandleFunc("/images/updates", dockerHandler.CheckImageUpdates).Methods("GET")
+	protected.HandleFunc("/images/build", dockerHandler.BuildImage).Methods("POST")
+	protected.HandleFunc("/images/build/{id}/stream", dockerHandler.StreamImageBuild).Methods("GET")
+	protected.HandleFunc("/images/import", dockerHandler.ImportImage).Methods("POST")
+	protected.HandleFunc("/images/import/{id}/stream", dockerHandler.StreamImageImport).Methods("GET")
+	protected.HandleFunc("/images/{id}", dockerHandler.GetImage).Methods("GET")
+	protected.HandleFunc("/images/{id}", dockerHandler.DeleteImage).Methods("DELETE")
+	protected.HandleFunc("/images/{id}/tag", dockerHandler.TagImage).Methods("POST")
+	protected.HandleFunc("/images/{id}/export", dockerHandler.ExportImage).Methods("GET")
+
+	// Docker network routes
+	protected.HandleFunc("/networks", dockerHandler.ListNetworks).Methods("GET")
+	protected.HandleFunc("/networks", dockerHandler.CreateNetwork).Methods("POST")
+	protected.HandleFunc("/networks/prune", dockerHandler.PruneNetworks).Methods("POST")
+	protected.HandleFunc("/networks/{id}", dockerHandler.GetNetwork).Methods("GET")
+	protected.HandleFunc("/networks/{id}", dockerHandler.DeleteNetwork).Methods("DELETE")
+	protected.HandleFunc("/networks/{id}/connect", dockerHandler.ConnectNetwork).Methods("POST")
+	protected.HandleFunc("/networks/{id}/disconnect", dockerHandler.DisconnectNetwork).Methods("POST")
+
+	// Network topology
+	protected.HandleFunc("/topology", dockerHandler.GetTopology).Methods("GET")
+
 	// Docker volume routes
 	protected.HandleFunc("/volumes", dockerHandler.ListVolumes).Methods("GET")
+	protected.HandleFunc("/volumes", dockerHandler.CreateVolume).Methods("POST")
+	protected.HandleFunc("/volumes/prune", dockerHandler.PruneVolumes).Methods("POST")
 	protected.HandleFunc("/volumes/{name}/files", dockerHandler.ExploreVolumeFiles).Methods("GET")
 	protected.HandleFunc("/volumes/{name}/file", dockerHandler.ReadVolumeFile).Methods("GET")
+	protected.HandleFunc("/volumes/{name}/file", dockerHandler.WriteVolumeFile).Methods("PUT")
+	protected.HandleFunc("/volumes/{name}/file", dockerHandler.DeleteVolumeFile).Methods("DELETE")
+	protected.HandleFunc("/volumes/{name}/files", dockerHandler.UploadVolumeFiles).Methods("POST")
+	protected.HandleFunc("/volumes/{name}/mkdir", dockerHandler.CreateVolumeDirectory).Methods("POST")
+	protected.HandleFunc("/volumes/{name}/move", dockerHandler.MoveVolumeFile).Methods("POST")
+	protected.HandleFunc("/volumes/{name}/download", dockerHandler.DownloadVolumeFile).Methods("GET", "HEAD")
+	protected.HandleFunc("/volumes/{name}/archive", dockerHandler.DownloadVolumeDirectory).Methods("GET")
+	protected.HandleFunc("/volumes/{name}/search", dockerHandler.SearchVolume).Methods("GET")
 	protected.HandleFunc("/volumes/{name}", dockerHandler.DeleteVolume).Methods("DELETE")
+	protected.HandleFunc("/volumes/{name}/backup", dockerHandler.BackupVolume).Methods("GET")
+	protected.HandleFunc("/volumes/{name}/restore", dockerHandler.RestoreVolume).Methods("POST")
+
+	// Volume backup routes
+	protected.HandleFunc("/backups", backupHandler.ListBackups).Methods("GET")
+	protected.HandleFunc("/backups/history", backupHandler.GetBackupHistory).Methods("GET")
+	protected.HandleFunc("/backups/run", backupHandler.RunBackup).Methods("POST")
+	protected.HandleFunc("/backups/{name}", backupHandler.DownloadBackup).Methods("GET")
+	protected.HandleFunc("/backups/{name}", backupHandler.DeleteBackup).Methods("DELETE")
 
 	// System configuration routes
 	protected.HandleFunc("/config", configHandler.GetConfig).Methods("GET")
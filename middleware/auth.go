@@ -64,6 +64,12 @@ func JWTAuth(jwtSecret string) func(http.Handler) http.Handler {
 	}
 }
 
+// Username returns the name of the user authenticated by JWTAuth, or "" if there is none
+func Username(r *http.Request) string {
+	username, _ := r.Context().Value(UserContextKey).(string)
+	return username
+}
+
 // CORS middleware to handle Cross-Origin Resource Sharing
 func CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,38 @@
+package models
+
+// BackupArchive represents a volume backup archive in the backup directory
+type BackupArchive struct {
+	Name      string `json:"name"` // File name, e.g. data_20261016-030000.tar.gz
+	Volume    string `json:"volume"`
+	Size      int64  `json:"size"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// BackupRun represents a finished run of scheduled or manual backups
+type BackupRun struct {
+	ID         string               `json:"id"`
+	Trigger    string               `json:"trigger"` // schedule, manual
+	StartedAt  int64                `json:"started_at"`
+	FinishedAt int64                `json:"finished_at"`
+	Success    bool                 `json:"success"`         // All volumes were backed up
+	Error      string               `json:"error,omitempty"` // Error that prevented the run, e.g. listing volumes
+	Volumes    []BackupVolumeResult `json:"volumes"`
+	Deleted    []string             `json:"deleted"` // Archives removed by retention after the run
+}
+
+// BackupVolumeResult represents the outcome of backing up a single volume
+type BackupVolumeResult struct {
+	Volume  string `json:"volume"`
+	Archive string `json:"archive,omitempty"`
+	Size    int64  `json:"size,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BackupStatus represents the backup settings, the scheduler state and the stored archives
+type BackupStatus struct {
+	Schedule string          `json:"schedule"`
+	NextRun  int64           `json:"next_run,omitempty"` // Unix time of the next scheduled run
+	Running  bool            `json:"running"`
+	Dir      string          `json:"dir"`
+	Archives []BackupArchive `json:"archives"`
+}
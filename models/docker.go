@@ -5,6 +5,7 @@ type ContainerInfo struct {
 	ID             string            `json:"id"`
 	Name           string            `json:"name"`
 	Image          string            `json:"image"`
+	ImageID        string            `json:"image_id,omitempty"` // Short ID of the image the container was created from
 	State          string            `json:"state"`
 	Status         string            `json:"status"`
 	Health         string            `json:"health"`
@@ -19,6 +20,7 @@ type ContainerInfo struct {
 	Ports          []PortBinding     `json:"ports,omitempty"`
 	Mounts         []MountInfo       `json:"mounts,omitempty"`
 	Hostname       string            `json:"hostname,omitempty"`
+	UpdateAvailable bool             `json:"update_available"` // A newer image is available for the container's tag (from the last update check)
 }
 
 // RestartPolicy represents container restart policy
@@ -32,7 +34,9 @@ type NetworkInfo struct {
 	NetworkID   string `json:"network_id"`
 	Gateway     string `json:"gateway,omitempty"`
 	IPAddress   string `json:"ip_address,omitempty"`
+	IPv6Address string `json:"ipv6_address,omitempty"`
 	MacAddress  string `json:"mac_address,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"` // Network-scoped DNS aliases, excluding the short container ID
 }
 
 // PortBinding represents port mapping
@@ -63,9 +67,34 @@ type VolumeInfo struct {
 	Mountpoint string   `json:"mountpoint"`
 	CreatedAt  string   `json:"created_at"`
 	Scope      string   `json:"scope"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Options    map[string]string `json:"options,omitempty"` // Driver options
 	Containers []string `json:"containers"` // List of container IDs using this volume
 }
 
+// VolumePruneResult represents the result of pruning unused volumes
+type VolumePruneResult struct {
+	DryRun         bool           `json:"dry_run"`
+	VolumesDeleted []string       `json:"volumes_deleted"`   // In a dry run, the volumes that would be deleted
+	Volumes        []PrunedVolume `json:"volumes,omitempty"` // Details of the volumes that would be deleted, only in a dry run
+	SpaceReclaimed uint64         `json:"space_reclaimed"`   // In a dry run, the estimated space that would be reclaimed
+}
+
+// VolumeRestoreResult represents the outcome of restoring a volume from an archive
+type VolumeRestoreResult struct {
+	Volume            string   `json:"volume"`
+	Cleared           bool     `json:"cleared"`            // Whether the previous contents were deleted
+	StoppedContainers []string `json:"stopped_containers"` // Containers stopped during the restore and started again
+}
+
+// PrunedVolume represents a volume selected for pruning
+type PrunedVolume struct {
+	Name      string            `json:"name"`
+	CreatedAt string            `json:"created_at"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Size      int64             `json:"size"` // Bytes, -1 if the size could not be determined
+}
+
 // Response represents a generic API response
 type Response struct {
 	Success bool        `json:"success"`
@@ -88,4 +117,284 @@ type VolumeFileContent struct {
 	Path    string `json:"path"`
 	Content string `json:"content"`
 	Size    int64  `json:"size"`
+	ModTime string `json:"mod_time,omitempty"` // Pass back as expected_mod_time when saving changes
+}
+
+// ExecMessage represents a control or input message sent over an exec WebSocket
+type ExecMessage struct {
+	Type string `json:"type"`           // input, resize
+	Data string `json:"data,omitempty"` // Stdin payload for input messages
+	Cols uint   `json:"cols,omitempty"` // Terminal width for resize messages
+	Rows uint   `json:"rows,omitempty"` // Terminal height for resize messages
+}
+
+// ContainerStats represents a single resource usage sample for a container
+type ContainerStats struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Read          string  `json:"read"`           // Sample timestamp (RFC3339)
+	CPUPercent    float64 `json:"cpu_percent"`    // CPU usage relative to a single core (can exceed 100 on multi-core hosts)
+	MemoryUsage   uint64  `json:"memory_usage"`   // Memory usage in bytes, excluding page cache
+	MemoryLimit   uint64  `json:"memory_limit"`   // Memory limit in bytes
+	MemoryPercent float64 `json:"memory_percent"` // Memory usage relative to the limit
+	NetworkRx     uint64  `json:"network_rx"`     // Total bytes received across all interfaces
+	NetworkTx     uint64  `json:"network_tx"`     // Total bytes sent across all interfaces
+	BlockRead     uint64  `json:"block_read"`     // Total bytes read from block devices
+	BlockWrite    uint64  `json:"block_write"`    // Total bytes written to block devices
+	PIDs          uint64  `json:"pids"`           // Number of processes
+}
+
+// ContainerStatsSnapshot pairs a running container with its current resource usage
+type ContainerStatsSnapshot struct {
+	ContainerInfo
+	Stats      *ContainerStats `json:"stats,omitempty"`
+	StatsError string          `json:"stats_error,omitempty"` // Set when the sample could not be collected
+}
+
+// ComposeProject represents a Docker Compose project and its services
+type ComposeProject struct {
+	Name     string           `json:"name"`
+	State    string           `json:"state"`   // running, partial, stopped
+	Running  int              `json:"running"` // Number of running containers
+	Total    int              `json:"total"`   // Total number of containers
+	Services []ComposeService `json:"services"`
+}
+
+// ComposeService represents a service within a Docker Compose project
+type ComposeService struct {
+	Name       string          `json:"name"`
+	State      string          `json:"state"`      // running, partial, stopped
+	DependsOn  []string        `json:"depends_on"` // Services this service depends on
+	Containers []ContainerInfo `json:"containers"`
+}
+
+// ComposeOperationResult represents the outcome of an operation on one container of a project
+type ComposeOperationResult struct {
+	ContainerID string `json:"container_id"`
+	Name        string `json:"name"`
+	Service     string `json:"service"`
+	Success     bool   `json:"success"`
+	Error       string `json:"error,omitempty"`
+}
+
+// StackAction describes a change to one resource of a stack
+type StackAction struct {
+	Type    string `json:"type"`              // network, volume, container
+	Name    string `json:"name"`              // Resource name
+	Service string `json:"service,omitempty"` // Compose service for container actions
+	Action  string `json:"action"`            // create, recreate, start, remove, unchanged
+	Reason  string `json:"reason,omitempty"`
+	Done    bool   `json:"done"` // Whether the action was applied
+	Error   string `json:"error,omitempty"`
+}
+
+// StackPlan represents the actions of a stack deployment or removal
+type StackPlan struct {
+	Name    string        `json:"name"`
+	DryRun  bool          `json:"dry_run"`
+	Actions []StackAction `json:"actions"`
+}
+
+// ImageInfo represents Docker image information
+type ImageInfo struct {
+	ID          string   `json:"id"`
+	RepoTags    []string `json:"repo_tags"`
+	RepoDigests []string `json:"repo_digests"`
+	Size        int64    `json:"size"`
+	Created     int64    `json:"created"`
+	Dangling    bool     `json:"dangling"`   // Image has no tags
+	Containers  []string `json:"containers"` // List of container IDs using this image
+}
+
+// ImageDetail represents detailed Docker image information including layers and history
+type ImageDetail struct {
+	ImageInfo
+	Architecture string              `json:"architecture"`
+	Os           string              `json:"os"`
+	Author       string              `json:"author,omitempty"`
+	Cmd          []string            `json:"cmd,omitempty"`
+	Entrypoint   []string            `json:"entrypoint,omitempty"`
+	Env          []string            `json:"env,omitempty"`
+	ExposedPorts []string            `json:"exposed_ports,omitempty"`
+	Labels       map[string]string   `json:"labels,omitempty"`
+	Layers       []string            `json:"layers"`
+	History      []ImageHistoryEntry `json:"history"`
+}
+
+// ImageHistoryEntry represents one layer-producing step in an image's history
+type ImageHistoryEntry struct {
+	ID        string   `json:"id"`
+	Created   int64    `json:"created"`
+	CreatedBy string   `json:"created_by"`
+	Size      int64    `json:"size"`
+	Tags      []string `json:"tags,omitempty"`
+	Comment   string   `json:"comment,omitempty"`
+}
+
+// ImageDeleteResult represents an image reference that was untagged or deleted
+type ImageDeleteResult struct {
+	Untagged string `json:"untagged,omitempty"`
+	Deleted  string `json:"deleted,omitempty"`
+}
+
+// ImagePruneResult represents the result of pruning unused images
+type ImagePruneResult struct {
+	ImagesDeleted  []ImageDeleteResult `json:"images_deleted"`
+	SpaceReclaimed uint64              `json:"space_reclaimed"`
+}
+
+// ImageLayerProgress represents the pull progress of a single image layer
+type ImageLayerProgress struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`  // e.g. Waiting, Downloading, Extracting, Pull complete, Already exists
+	Current int64  `json:"current"` // Bytes processed in the current phase
+	Total   int64  `json:"total"`   // Layer size in bytes, 0 if unknown
+}
+
+// ImagePullProgress represents the aggregated progress of an image pull
+type ImagePullProgress struct {
+	Image      string               `json:"image"`
+	Status     string               `json:"status"`     // Latest image-level status message
+	Layers     []ImageLayerProgress `json:"layers"`     // Layers in the order they were first reported
+	Downloaded int64                `json:"downloaded"` // Bytes downloaded across all layers
+	Total      int64                `json:"total"`      // Known size of all layers being downloaded
+	Done       bool                 `json:"done"`
+	Digest     string               `json:"digest,omitempty"`   // Manifest digest, set when done
+	ImageID    string               `json:"image_id,omitempty"` // Local image ID, set when done
+}
+
+// RecreateResult represents the outcome of recreating a container
+type RecreateResult struct {
+	Name           string `json:"name"`
+	Image          string `json:"image"`
+	OldContainerID string `json:"old_container_id"`
+	NewContainerID string `json:"new_container_id,omitempty"`
+	OldImageID     string `json:"old_image_id"`
+	NewImageID     string `json:"new_image_id,omitempty"`
+	Pulled         bool   `json:"pulled"`
+	ImageUpdated   bool   `json:"image_updated"` // The new container uses a different image than the old one
+	RolledBack     bool   `json:"rolled_back"`   // The new container failed and the old one was restored
+	RollbackError  string `json:"rollback_error,omitempty"`
+}
+
+// ImageUpdateStatus represents the result of checking a container's image against its registry
+type ImageUpdateStatus struct {
+	ContainerID     string `json:"container_id"`
+	ContainerName   string `json:"container_name"`
+	Image           string `json:"image"`
+	ImageID         string `json:"image_id"`
+	LocalDigest     string `json:"local_digest,omitempty"`
+	RemoteDigest    string `json:"remote_digest,omitempty"`
+	UpdateAvailable bool   `json:"update_available"`
+	Skipped         string `json:"skipped,omitempty"`    // Reason the container was not checked
+	Error           string `json:"error,omitempty"`      // Registry lookup error
+	CheckedAt       int64  `json:"checked_at,omitempty"` // When the registry was last queried
+}
+
+// Network represents a Docker network with the containers attached to it
+type Network struct {
+	ID         string             `json:"id"`
+	Name       string             `json:"name"`
+	Driver     string             `json:"driver"`
+	Scope      string             `json:"scope"`
+	Internal   bool               `json:"internal"`
+	Attachable bool               `json:"attachable"`
+	EnableIPv6 bool               `json:"enable_ipv6"`
+	Builtin    bool               `json:"builtin"` // Predefined network (bridge, host, none) that cannot be removed
+	Created    int64              `json:"created"`
+	Subnets    []NetworkSubnet    `json:"subnets"`
+	Options    map[string]string  `json:"options,omitempty"`
+	Labels     map[string]string  `json:"labels,omitempty"`
+	Containers []NetworkContainer `json:"containers"`
+}
+
+// NetworkSubnet represents an IPAM pool of a network
+type NetworkSubnet struct {
+	Subnet  string `json:"subnet"`
+	Gateway string `json:"gateway,omitempty"`
+	IPRange string `json:"ip_range,omitempty"`
+}
+
+// NetworkContainer represents a container's endpoint on a network
+type NetworkContainer struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	State       string   `json:"state"`
+	IPv4Address string   `json:"ipv4_address,omitempty"`
+	IPv6Address string   `json:"ipv6_address,omitempty"`
+	MacAddress  string   `json:"mac_address,omitempty"`
+	Aliases     []string `json:"aliases"`
+}
+
+// Topology is a graph of containers and the networks they are attached to
+type Topology struct {
+	Nodes []TopologyNode `json:"nodes"`
+	Edges []TopologyEdge `json:"edges"`
+}
+
+// TopologyNode is a container or a network in the topology graph
+type TopologyNode struct {
+	ID             string        `json:"id"`   // "container:<id>" or "network:<id>"
+	Type           string        `json:"type"` // container, network
+	Name           string        `json:"name"`
+	State          string        `json:"state,omitempty"`
+	Image          string        `json:"image,omitempty"`
+	ComposeProject string        `json:"compose_project,omitempty"`
+	IsSelf         bool          `json:"is_self,omitempty"`
+	Ports          []PortBinding `json:"ports,omitempty"` // Ports published on the host
+	Driver         string        `json:"driver,omitempty"`
+	Internal       bool          `json:"internal,omitempty"`
+	Subnets        []string      `json:"subnets,omitempty"`
+}
+
+// TopologyEdge attaches a container node to a network node
+type TopologyEdge struct {
+	Source      string   `json:"source"` // Container node ID
+	Target      string   `json:"target"` // Network node ID
+	IPv4Address string   `json:"ipv4_address,omitempty"`
+	IPv6Address string   `json:"ipv6_address,omitempty"`
+	MacAddress  string   `json:"mac_address,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+}
+
+// ContainerUploadResult reports the files copied into a container
+type ContainerUploadResult struct {
+	Directory   string   `json:"directory"`
+	Files       []string `json:"files"`       // Paths of the copied files, without directories
+	Overwritten []string `json:"overwritten"` // Paths of files that existed before
+}
+
+// ContainerChanges is the tree of paths changed in a container's writable layer
+type ContainerChanges struct {
+	Root     *ContainerChangeNode `json:"root"`
+	Added    int                  `json:"added"`
+	Modified int                  `json:"modified"`
+	Deleted  int                  `json:"deleted"`
+	SizeRw   *int64               `json:"size_rw,omitempty"` // Size of the writable layer, with sizes
+	// With sizes, set when too many paths changed to look up their sizes
+	SizesOmitted bool `json:"sizes_omitted,omitempty"`
+}
+
+// ContainerChangeNode is a path in the tree of container changes
+type ContainerChangeNode struct {
+	Name        string                 `json:"name"`
+	Path        string                 `json:"path"`
+	Kind        string                 `json:"kind,omitempty"` // added, modified, deleted; empty for unchanged parents
+	IsDirectory bool                   `json:"is_directory,omitempty"`
+	Size        *int64                 `json:"size,omitempty"` // With sizes, the file size or the total of the files below a directory
+	Children    []*ContainerChangeNode `json:"children,omitempty"`
+}
+
+// VolumeSearchMatch is a file of a volume matching a search, with the matching line for content searches
+type VolumeSearchMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"` // 1-based line number, for content searches
+	Text string `json:"text,omitempty"` // Matching line, shortened if very long
+}
+
+// VolumeSearchResult summarizes a finished volume search
+type VolumeSearchResult struct {
+	Done      bool `json:"done"`
+	Matches   int  `json:"matches"`
+	Truncated bool `json:"truncated"` // More matches were found than max_results
 }
@@ -0,0 +1,321 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+)
+
+// dockerHubHost is the domain used in normalized Docker Hub references
+const dockerHubHost = "docker.io"
+
+// dockerHubAPIHost serves the distribution API for Docker Hub
+const dockerHubAPIHost = "registry-1.docker.io"
+
+// requestTimeout bounds each request made to a registry
+const requestTimeout = 30 * time.Second
+
+// manifestMediaTypes are the manifest formats accepted when resolving a digest. Indexes are
+// listed first so multi-platform images resolve to the same digest Docker records on pull.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// ErrUnauthorized is returned when a registry rejects the configured credentials (or their absence)
+var ErrUnauthorized = errors.New("registry authentication failed")
+
+// Host holds the connection settings for a registry
+type Host struct {
+	Username string
+	Password string // Password or access token
+	Insecure bool   // Use plain HTTP instead of HTTPS
+}
+
+// Client resolves image references against registries using the distribution HTTP API
+type Client struct {
+	httpClient *http.Client
+	mu         sync.RWMutex
+	hosts      map[string]Host
+}
+
+// NewClient creates a registry client with per-registry settings keyed by registry host
+// (e.g. "ghcr.io", "registry.example.com:5000" or "docker.io" for Docker Hub)
+func NewClient(hosts map[string]Host) *Client {
+	c := &Client{
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+	c.SetHosts(hosts)
+	return c
+}
+
+// SetHosts replaces the per-registry settings
+func (c *Client) SetHosts(hosts map[string]Host) {
+	copied := make(map[string]Host, len(hosts))
+	for name, host := range hosts {
+		copied[name] = host
+	}
+
+	c.mu.Lock()
+	c.hosts = copied
+	c.mu.Unlock()
+}
+
+// host returns the settings for a registry domain
+func (c *Client) host(domain string) Host {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.hosts[domain]
+}
+
+// ManifestDigest returns the digest of the manifest a tagged reference currently points to.
+// References without a tag resolve "latest"; references pinned to a digest return that digest.
+func (c *Client) ManifestDigest(ctx context.Context, ref string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	if canonical, ok := named.(reference.Canonical); ok {
+		return canonical.Digest().String(), nil
+	}
+
+	tagged := reference.TagNameOnly(named).(reference.Tagged)
+	domain := reference.Domain(named)
+	settings := c.host(domain)
+
+	apiHost := domain
+	if domain == dockerHubHost {
+		apiHost = dockerHubAPIHost
+	}
+	scheme := "https"
+	if settings.Insecure || isLocalHost(apiHost) {
+		scheme = "http"
+	}
+
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, apiHost, reference.Path(named), tagged.Tag())
+	session := &authSession{client: c, host: settings, repository: reference.Path(named)}
+
+	resp, err := session.do(ctx, http.MethodHead, manifestURL)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	// Some registries only send the digest header on GET; fall back to hashing the manifest
+	resp, err = session.do(ctx, http.MethodGet, manifestURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// authSession performs registry requests for one repository, authenticating on demand
+type authSession struct {
+	client        *Client
+	host          Host
+	repository    string
+	authorization string
+}
+
+// do sends a manifest request, answering an authentication challenge once if needed.
+// Only 200 responses are returned; the caller must close the body.
+func (s *authSession) do(ctx context.Context, method, target string) (*http.Response, error) {
+	resp, err := s.send(ctx, method, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && s.authorization == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		if s.authorization, err = s.authorize(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = s.send(ctx, method, target); err != nil {
+			return nil, err
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		resp.Body.Close()
+		return nil, ErrUnauthorized
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry returned %s for %s", resp.Status, target)
+	}
+}
+
+// send issues a single manifest request
+func (s *authSession) send(ctx context.Context, method, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if s.authorization != "" {
+		req.Header.Set("Authorization", s.authorization)
+	}
+
+	resp, err := s.client.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("registry request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// authorize answers a WWW-Authenticate challenge and returns the Authorization header value
+func (s *authSession) authorize(ctx context.Context, challenge string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if s.host.Username == "" {
+			return "", ErrUnauthorized
+		}
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(s.host.Username, s.host.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		token, err := s.fetchToken(ctx, params)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("unsupported registry authentication challenge %q", challenge)
+	}
+}
+
+// fetchToken requests a bearer token from the registry's token service
+func (s *authSession) fetchToken(ctx context.Context, params map[string]string) (string, error) {
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry token challenge has no realm")
+	}
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid registry token realm: %w", err)
+	}
+
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + s.repository + ":pull"
+	}
+	query := tokenURL.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if s.host.Username != "" {
+		req.SetBasicAuth(s.host.Username, s.host.Password)
+	}
+
+	resp, err := s.client.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("registry token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return "", ErrUnauthorized
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry token service returned %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid registry token response: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("registry token response contains no token")
+}
+
+// parseChallenge parses a WWW-Authenticate header such as
+// `Bearer realm="https://auth.example.com/token",service="registry",scope="repository:app:pull"`
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := make(map[string]string)
+
+	for rest != "" {
+		rest = strings.TrimLeft(rest, " ,")
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		if strings.HasPrefix(value, `"`) {
+			// Quoted values may contain commas, e.g. "repository:app:pull,push"
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key] = value[1 : end+1]
+			rest = value[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(value, ",")
+			params[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return scheme, params
+}
+
+// isLocalHost reports whether a registry host is on the loopback interface, where
+// Docker also allows plain HTTP
+func isLocalHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,216 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:4b825dc642cb6eb9a060e54bf8d69288fbee4904b825dc642cb6eb9a060e54bf"
+
+// serverHost returns the host:port of a test server
+func serverHost(server *httptest.Server) string {
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestManifestDigestTokenAuth(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			username, password, ok := r.BasicAuth()
+			if !ok || username != "user" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if got := r.URL.Query().Get("scope"); got != "repository:team/app:pull" {
+				t.Errorf("token scope = %q, want repository:team/app:pull", got)
+			}
+			if got := r.URL.Query().Get("service"); got != "test-registry" {
+				t.Errorf("token service = %q, want test-registry", got)
+			}
+			w.Write([]byte(`{"token": "abc123"}`))
+		case "/v2/team/app/manifests/1.2":
+			if r.Header.Get("Authorization") != "Bearer abc123" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test-registry"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", testDigest)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	host := serverHost(server)
+	client := NewClient(map[string]Host{host: {Username: "user", Password: "secret"}})
+	digest, err := client.ManifestDigest(context.Background(), host+"/team/app:1.2")
+	if err != nil {
+		t.Fatalf("ManifestDigest() error = %v", err)
+	}
+	if digest != testDigest {
+		t.Errorf("ManifestDigest() = %q, want %q", digest, testDigest)
+	}
+
+	// Wrong credentials are rejected by the token service
+	client.SetHosts(map[string]Host{host: {Username: "user", Password: "wrong"}})
+	if _, err := client.ManifestDigest(context.Background(), host+"/team/app:1.2"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("ManifestDigest() with wrong credentials error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestManifestDigestBasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", testDigest)
+	}))
+	defer server.Close()
+
+	host := serverHost(server)
+	client := NewClient(map[string]Host{host: {Username: "user", Password: "secret"}})
+	if digest, err := client.ManifestDigest(context.Background(), host+"/app"); err != nil || digest != testDigest {
+		t.Errorf("ManifestDigest() = %q, %v, want %q", digest, err, testDigest)
+	}
+
+	// Without credentials, the challenge cannot be answered
+	client.SetHosts(nil)
+	if _, err := client.ManifestDigest(context.Background(), host+"/app"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("ManifestDigest() without credentials error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestManifestDigestManifestList(t *testing.T) {
+	manifestList := `{"schemaVersion":2,"mediaType":"application/vnd.docker.distribution.manifest.list.v2+json","manifests":[]}`
+	sum := sha256.Sum256([]byte(manifestList))
+	want := "sha256:" + hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/library/app/manifests/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		// Indexes must be preferred so the digest matches the one Docker records on pull
+		accept := r.Header.Get("Accept")
+		if !strings.HasPrefix(accept, "application/vnd.oci.image.index.v1+json") ||
+			!strings.Contains(accept, "application/vnd.docker.distribution.manifest.list.v2+json") {
+			t.Errorf("Accept = %q, want manifest lists first", accept)
+		}
+		// No digest header, so the digest is computed from the manifest list itself
+		w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.list.v2+json")
+		if r.Method == http.MethodGet {
+			w.Write([]byte(manifestList))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	digest, err := client.ManifestDigest(context.Background(), serverHost(server)+"/library/app")
+	if err != nil {
+		t.Fatalf("ManifestDigest() error = %v", err)
+	}
+	if digest != want {
+		t.Errorf("ManifestDigest() = %q, want %q", digest, want)
+	}
+}
+
+func TestManifestDigestInsecureHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/app/manifests/1.0" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", testDigest)
+	}))
+	defer server.Close()
+
+	// A registry host outside the loopback interface, served by the test server
+	const host = "registry.test:5000"
+	client := NewClient(nil)
+	dialer := &net.Dialer{}
+	client.httpClient.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if addr == host {
+				addr = serverHost(server)
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+
+	// HTTPS is used unless the host is marked insecure
+	if _, err := client.ManifestDigest(context.Background(), host+"/app:1.0"); err == nil {
+		t.Error("ManifestDigest() over HTTPS against a plain HTTP registry succeeded")
+	}
+
+	client.SetHosts(map[string]Host{host: {Insecure: true}})
+	digest, err := client.ManifestDigest(context.Background(), host+"/app:1.0")
+	if err != nil {
+		t.Fatalf("ManifestDigest() error = %v", err)
+	}
+	if digest != testDigest {
+		t.Errorf("ManifestDigest() = %q, want %q", digest, testDigest)
+	}
+}
+
+func TestManifestDigestPinned(t *testing.T) {
+	// References pinned to a digest are resolved without contacting the registry
+	client := NewClient(nil)
+	digest, err := client.ManifestDigest(context.Background(), "registry.invalid/app@"+testDigest)
+	if err != nil {
+		t.Fatalf("ManifestDigest() error = %v", err)
+	}
+	if digest != testDigest {
+		t.Errorf("ManifestDigest() = %q, want %q", digest, testDigest)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	tests := []struct {
+		header     string
+		wantScheme string
+		wantParams map[string]string
+	}{
+		{
+			header:     `Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:app:pull,push"`,
+			wantScheme: "Bearer",
+			wantParams: map[string]string{"realm": "https://auth.example.com/token", "service": "registry.example.com", "scope": "repository:app:pull,push"},
+		},
+		{
+			header:     `Basic realm="Registry Realm"`,
+			wantScheme: "Basic",
+			wantParams: map[string]string{"realm": "Registry Realm"},
+		},
+		{
+			header:     `Bearer realm=https://auth.example.com/token, service=registry`,
+			wantScheme: "Bearer",
+			wantParams: map[string]string{"realm": "https://auth.example.com/token", "service": "registry"},
+		},
+	}
+
+	for _, tt := range tests {
+		scheme, params := parseChallenge(tt.header)
+		if scheme != tt.wantScheme {
+			t.Errorf("parseChallenge(%q) scheme = %q, want %q", tt.header, scheme, tt.wantScheme)
+		}
+		if len(params) != len(tt.wantParams) {
+			t.Errorf("parseChallenge(%q) params = %v, want %v", tt.header, params, tt.wantParams)
+			continue
+		}
+		for key, want := range tt.wantParams {
+			if params[key] != want {
+				t.Errorf("parseChallenge(%q) %s = %q, want %q", tt.header, key, params[key], want)
+			}
+		}
+	}
+}
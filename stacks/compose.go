@@ -0,0 +1,372 @@
+package stacks
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// projectNamePattern matches valid Docker Compose project names
+var projectNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ComposeFile represents the subset of the Compose specification supported by stacks.
+// Unknown keys (including "version" and "x-" extensions) are ignored.
+type ComposeFile struct {
+	Services map[string]*Service `yaml:"services" json:"services"`
+	Networks map[string]*Network `yaml:"networks" json:"networks,omitempty"`
+	Volumes  map[string]*Volume  `yaml:"volumes" json:"volumes,omitempty"`
+}
+
+// Service represents a Compose service definition
+type Service struct {
+	Image         string          `yaml:"image" json:"image"`
+	Build         interface{}     `yaml:"build" json:"-"`
+	ContainerName string          `yaml:"container_name" json:"container_name,omitempty"`
+	Command       StringOrList    `yaml:"command" json:"command,omitempty"`
+	Entrypoint    StringOrList    `yaml:"entrypoint" json:"entrypoint,omitempty"`
+	Environment   MappingOrList   `yaml:"environment" json:"environment,omitempty"`
+	Labels        MappingOrList   `yaml:"labels" json:"labels,omitempty"`
+	Ports         ServicePorts    `yaml:"ports" json:"ports,omitempty"`
+	Volumes       []ServiceVolume `yaml:"volumes" json:"volumes,omitempty"`
+	Networks      ServiceNetworks `yaml:"networks" json:"networks,omitempty"`
+	DependsOn     DependsOn       `yaml:"depends_on" json:"depends_on,omitempty"`
+	Restart       string          `yaml:"restart" json:"restart,omitempty"`
+	Hostname      string          `yaml:"hostname" json:"hostname,omitempty"`
+	User          string          `yaml:"user" json:"user,omitempty"`
+	WorkingDir    string          `yaml:"working_dir" json:"working_dir,omitempty"`
+	Privileged    bool            `yaml:"privileged" json:"privileged,omitempty"`
+}
+
+// Network represents a top-level Compose network definition
+type Network struct {
+	Name       string            `yaml:"name" json:"name,omitempty"`
+	Driver     string            `yaml:"driver" json:"driver,omitempty"`
+	DriverOpts map[string]string `yaml:"driver_opts" json:"driver_opts,omitempty"`
+	External   bool              `yaml:"external" json:"external,omitempty"`
+	Internal   bool              `yaml:"internal" json:"internal,omitempty"`
+	Attachable bool              `yaml:"attachable" json:"attachable,omitempty"`
+	Labels     MappingOrList     `yaml:"labels" json:"labels,omitempty"`
+}
+
+// Volume represents a top-level Compose volume definition
+type Volume struct {
+	Name       string            `yaml:"name" json:"name,omitempty"`
+	Driver     string            `yaml:"driver" json:"driver,omitempty"`
+	DriverOpts map[string]string `yaml:"driver_opts" json:"driver_opts,omitempty"`
+	External   bool              `yaml:"external" json:"external,omitempty"`
+	Labels     MappingOrList     `yaml:"labels" json:"labels,omitempty"`
+}
+
+// ServiceVolume represents a volume or bind mount of a service (short or long syntax)
+type ServiceVolume struct {
+	Type     string `yaml:"type" json:"type"` // volume, bind
+	Source   string `yaml:"source" json:"source,omitempty"`
+	Target   string `yaml:"target" json:"target"`
+	ReadOnly bool   `yaml:"read_only" json:"read_only,omitempty"`
+}
+
+// ServiceNetwork represents the attachment of a service to a network
+type ServiceNetwork struct {
+	Aliases     []string `yaml:"aliases" json:"aliases,omitempty"`
+	IPv4Address string   `yaml:"ipv4_address" json:"ipv4_address,omitempty"`
+}
+
+// StringOrList is a command that may be written as a string or a list
+type StringOrList []string
+
+// MappingOrList is a string map that may be written as a mapping or a list of KEY=VALUE entries
+type MappingOrList map[string]string
+
+// ServiceNetworks maps network names to attachment options; it may be written as a list of names
+type ServiceNetworks map[string]*ServiceNetwork
+
+// ServicePorts lists published ports in the short "[host_ip:][published:]target[/protocol]" form;
+// entries may be written in the long mapping syntax
+type ServicePorts []string
+
+// DependsOn lists service dependencies; it may be written as a list or a mapping with conditions
+type DependsOn map[string]string
+
+// UnmarshalYAML accepts both "cmd arg" and ["cmd", "arg"]
+func (s *StringOrList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*s = strings.Fields(node.Value)
+		return nil
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		*s = list
+		return nil
+	}
+	return fmt.Errorf("line %d: expected a string or a list", node.Line)
+}
+
+// UnmarshalYAML accepts both {KEY: value} and ["KEY=value"]
+func (m *MappingOrList) UnmarshalYAML(node *yaml.Node) error {
+	result := make(map[string]string)
+	switch node.Kind {
+	case yaml.MappingNode:
+		var raw map[string]interface{}
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		for k, v := range raw {
+			if v == nil {
+				result[k] = ""
+			} else {
+				result[k] = fmt.Sprint(v)
+			}
+		}
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		for _, entry := range list {
+			key, value, _ := strings.Cut(entry, "=")
+			result[key] = value
+		}
+	default:
+		return fmt.Errorf("line %d: expected a mapping or a list", node.Line)
+	}
+	*m = result
+	return nil
+}
+
+// UnmarshalYAML accepts both [net1, net2] and {net1: {aliases: [...]}}
+func (n *ServiceNetworks) UnmarshalYAML(node *yaml.Node) error {
+	result := make(map[string]*ServiceNetwork)
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		for _, name := range list {
+			result[name] = &ServiceNetwork{}
+		}
+	case yaml.MappingNode:
+		var raw map[string]*ServiceNetwork
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		for name, attachment := range raw {
+			if attachment == nil {
+				attachment = &ServiceNetwork{}
+			}
+			result[name] = attachment
+		}
+	default:
+		return fmt.Errorf("line %d: expected a list or a mapping of networks", node.Line)
+	}
+	*n = result
+	return nil
+}
+
+// UnmarshalYAML accepts both ["8080:80"] and [{target: 80, published: 8080}]
+func (p *ServicePorts) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		return fmt.Errorf("line %d: expected a list of ports", node.Line)
+	}
+
+	result := make([]string, 0, len(node.Content))
+	for _, entry := range node.Content {
+		switch entry.Kind {
+		case yaml.ScalarNode:
+			result = append(result, entry.Value)
+		case yaml.MappingNode:
+			var long struct {
+				Target    string `yaml:"target"`
+				Published string `yaml:"published"`
+				HostIP    string `yaml:"host_ip"`
+				Protocol  string `yaml:"protocol"`
+			}
+			if err := entry.Decode(&long); err != nil {
+				return err
+			}
+			if long.Target == "" {
+				return fmt.Errorf("line %d: port target is required", entry.Line)
+			}
+			port := long.Target
+			if long.Published != "" || long.HostIP != "" {
+				port = long.Published + ":" + port
+			}
+			if long.HostIP != "" {
+				port = long.HostIP + ":" + port
+			}
+			if long.Protocol != "" {
+				port += "/" + long.Protocol
+			}
+			result = append(result, port)
+		default:
+			return fmt.Errorf("line %d: expected a port string or mapping", entry.Line)
+		}
+	}
+	*p = result
+	return nil
+}
+
+// UnmarshalYAML accepts both [db] and {db: {condition: service_healthy}}
+func (d *DependsOn) UnmarshalYAML(node *yaml.Node) error {
+	result := make(map[string]string)
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		for _, name := range list {
+			result[name] = "service_started"
+		}
+	case yaml.MappingNode:
+		var raw map[string]struct {
+			Condition string `yaml:"condition"`
+		}
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		for name, dep := range raw {
+			condition := dep.Condition
+			if condition == "" {
+				condition = "service_started"
+			}
+			result[name] = condition
+		}
+	default:
+		return fmt.Errorf("line %d: expected a list or a mapping of services", node.Line)
+	}
+	*d = result
+	return nil
+}
+
+// UnmarshalYAML accepts both "source:target[:mode]" and the long mapping syntax
+func (v *ServiceVolume) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		type plain ServiceVolume
+		var long plain
+		if err := node.Decode(&long); err != nil {
+			return err
+		}
+		*v = ServiceVolume(long)
+		if v.Type == "" {
+			v.Type = "volume"
+		}
+		return nil
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected a volume string or mapping", node.Line)
+	}
+
+	parts := strings.Split(node.Value, ":")
+	switch len(parts) {
+	case 1:
+		// Anonymous volume
+		*v = ServiceVolume{Type: "volume", Target: parts[0]}
+	case 2, 3:
+		*v = ServiceVolume{Type: "volume", Source: parts[0], Target: parts[1]}
+		if len(parts) == 3 {
+			for _, opt := range strings.Split(parts[2], ",") {
+				if opt == "ro" {
+					v.ReadOnly = true
+				}
+			}
+		}
+		if isBindSource(v.Source) {
+			v.Type = "bind"
+		}
+	default:
+		return fmt.Errorf("line %d: invalid volume specification %q", node.Line, node.Value)
+	}
+	return nil
+}
+
+// isBindSource reports whether a short-syntax volume source is a host path
+func isBindSource(source string) bool {
+	return strings.HasPrefix(source, "/") || strings.HasPrefix(source, ".") || strings.HasPrefix(source, "~")
+}
+
+// ValidateProjectName checks that a stack name is a valid Compose project name
+func ValidateProjectName(name string) error {
+	if !projectNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid stack name %q: must contain only lowercase letters, digits, '-' and '_' and start with a letter or digit", name)
+	}
+	return nil
+}
+
+// Parse parses and validates a Compose YAML document
+func Parse(content []byte) (*ComposeFile, error) {
+	var file ComposeFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid compose file: %w", err)
+	}
+
+	if len(file.Services) == 0 {
+		return nil, fmt.Errorf("invalid compose file: no services defined")
+	}
+
+	for name, service := range file.Services {
+		if service == nil {
+			return nil, fmt.Errorf("service %s: definition is empty", name)
+		}
+		if service.Build != nil {
+			return nil, fmt.Errorf("service %s: build is not supported, use a prebuilt image", name)
+		}
+		if service.Image == "" {
+			return nil, fmt.Errorf("service %s: image is required", name)
+		}
+		for dep := range service.DependsOn {
+			if _, ok := file.Services[dep]; !ok {
+				return nil, fmt.Errorf("service %s: depends on undefined service %s", name, dep)
+			}
+		}
+		for network := range service.Networks {
+			if _, ok := file.Networks[network]; !ok && network != "default" {
+				return nil, fmt.Errorf("service %s: uses undefined network %s", name, network)
+			}
+		}
+		for _, volume := range service.Volumes {
+			if volume.Target == "" {
+				return nil, fmt.Errorf("service %s: volume target is required", name)
+			}
+			if volume.Type == "volume" && volume.Source != "" {
+				if _, ok := file.Volumes[volume.Source]; !ok {
+					return nil, fmt.Errorf("service %s: uses undefined volume %s", name, volume.Source)
+				}
+			}
+			if volume.Type != "volume" && volume.Type != "bind" {
+				return nil, fmt.Errorf("service %s: unsupported volume type %s", name, volume.Type)
+			}
+		}
+	}
+
+	// Normalize empty top-level entries such as "volumes: {data: }"
+	for name, network := range file.Networks {
+		if network == nil {
+			file.Networks[name] = &Network{}
+		}
+	}
+	for name, volume := range file.Volumes {
+		if volume == nil {
+			file.Volumes[name] = &Volume{}
+		}
+	}
+
+	return &file, nil
+}
+
+// ServiceNames returns the service names in alphabetical order
+func (f *ComposeFile) ServiceNames() []string {
+	names := make([]string, 0, len(f.Services))
+	for name := range f.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
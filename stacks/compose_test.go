@@ -0,0 +1,266 @@
+package stacks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		check   func(t *testing.T, file *ComposeFile)
+		wantErr string
+	}{
+		{
+			name: "short volume syntax",
+			content: `
+services:
+  web:
+    image: nginx
+    volumes:
+      - data:/var/lib/data
+      - ./conf:/etc/nginx/conf.d:ro
+      - /srv/logs:/var/log/nginx:rw
+      - /cache
+volumes:
+  data:
+`,
+			check: func(t *testing.T, file *ComposeFile) {
+				want := []ServiceVolume{
+					{Type: "volume", Source: "data", Target: "/var/lib/data"},
+					{Type: "bind", Source: "./conf", Target: "/etc/nginx/conf.d", ReadOnly: true},
+					{Type: "bind", Source: "/srv/logs", Target: "/var/log/nginx"},
+					{Type: "volume", Target: "/cache"},
+				}
+				if got := file.Services["web"].Volumes; !reflect.DeepEqual(got, want) {
+					t.Errorf("volumes = %+v, want %+v", got, want)
+				}
+				if file.Volumes["data"] == nil {
+					t.Error("empty top-level volume was not normalized")
+				}
+			},
+		},
+		{
+			name: "long volume syntax",
+			content: `
+services:
+  web:
+    image: nginx
+    volumes:
+      - type: bind
+        source: ./html
+        target: /usr/share/nginx/html
+        read_only: true
+      - source: data
+        target: /data
+volumes:
+  data: {}
+`,
+			check: func(t *testing.T, file *ComposeFile) {
+				want := []ServiceVolume{
+					{Type: "bind", Source: "./html", Target: "/usr/share/nginx/html", ReadOnly: true},
+					{Type: "volume", Source: "data", Target: "/data"},
+				}
+				if got := file.Services["web"].Volumes; !reflect.DeepEqual(got, want) {
+					t.Errorf("volumes = %+v, want %+v", got, want)
+				}
+			},
+		},
+		{
+			name: "short and long port syntax",
+			content: `
+services:
+  web:
+    image: nginx
+    ports:
+      - "8080:80"
+      - 443
+      - target: 53
+        published: 5353
+        protocol: udp
+      - target: 9000
+        host_ip: 127.0.0.1
+        published: "9000"
+      - target: 22
+        host_ip: 127.0.0.1
+`,
+			check: func(t *testing.T, file *ComposeFile) {
+				want := ServicePorts{"8080:80", "443", "5353:53/udp", "127.0.0.1:9000:9000", "127.0.0.1::22"}
+				if got := file.Services["web"].Ports; !reflect.DeepEqual(got, want) {
+					t.Errorf("ports = %q, want %q", got, want)
+				}
+			},
+		},
+		{
+			name: "depends_on list",
+			content: `
+services:
+  web:
+    image: nginx
+    depends_on: [db, cache]
+  db:
+    image: postgres
+  cache:
+    image: redis
+`,
+			check: func(t *testing.T, file *ComposeFile) {
+				want := DependsOn{"db": "service_started", "cache": "service_started"}
+				if got := file.Services["web"].DependsOn; !reflect.DeepEqual(got, want) {
+					t.Errorf("depends_on = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "depends_on map",
+			content: `
+services:
+  web:
+    image: nginx
+    depends_on:
+      db:
+        condition: service_healthy
+      cache: {}
+  db:
+    image: postgres
+  cache:
+    image: redis
+`,
+			check: func(t *testing.T, file *ComposeFile) {
+				want := DependsOn{"db": "service_healthy", "cache": "service_started"}
+				if got := file.Services["web"].DependsOn; !reflect.DeepEqual(got, want) {
+					t.Errorf("depends_on = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "command, environment and networks",
+			content: `
+services:
+  web:
+    image: nginx
+    command: nginx -g "daemon off;"
+    entrypoint: ["/docker-entrypoint.sh"]
+    environment:
+      - MODE=prod
+      - EMPTY
+    networks: [front]
+networks:
+  front:
+`,
+			check: func(t *testing.T, file *ComposeFile) {
+				web := file.Services["web"]
+				if want := (StringOrList{"nginx", "-g", `"daemon`, `off;"`}); !reflect.DeepEqual(web.Command, want) {
+					t.Errorf("command = %q, want %q", web.Command, want)
+				}
+				if want := (StringOrList{"/docker-entrypoint.sh"}); !reflect.DeepEqual(web.Entrypoint, want) {
+					t.Errorf("entrypoint = %q, want %q", web.Entrypoint, want)
+				}
+				if want := (MappingOrList{"MODE": "prod", "EMPTY": ""}); !reflect.DeepEqual(web.Environment, want) {
+					t.Errorf("environment = %v, want %v", web.Environment, want)
+				}
+				if _, ok := web.Networks["front"]; !ok {
+					t.Errorf("networks = %v, want front", web.Networks)
+				}
+			},
+		},
+		{
+			name:    "invalid yaml",
+			content: "services: [",
+			wantErr: "invalid compose file",
+		},
+		{
+			name:    "no services",
+			content: "version: '3'\n",
+			wantErr: "no services defined",
+		},
+		{
+			name:    "empty service",
+			content: "services:\n  web:\n",
+			wantErr: "definition is empty",
+		},
+		{
+			name:    "missing image",
+			content: "services:\n  web:\n    command: true\n",
+			wantErr: "image is required",
+		},
+		{
+			name:    "build",
+			content: "services:\n  web:\n    image: app\n    build: .\n",
+			wantErr: "build is not supported",
+		},
+		{
+			name:    "undefined dependency",
+			content: "services:\n  web:\n    image: nginx\n    depends_on: [db]\n",
+			wantErr: "depends on undefined service db",
+		},
+		{
+			name:    "undefined network",
+			content: "services:\n  web:\n    image: nginx\n    networks: [back]\n",
+			wantErr: "uses undefined network back",
+		},
+		{
+			name:    "undefined volume",
+			content: "services:\n  web:\n    image: nginx\n    volumes: [data:/data]\n",
+			wantErr: "uses undefined volume data",
+		},
+		{
+			name:    "unsupported volume type",
+			content: "services:\n  web:\n    image: nginx\n    volumes:\n      - type: tmpfs\n        target: /tmp\n",
+			wantErr: "unsupported volume type tmpfs",
+		},
+		{
+			name:    "invalid short volume",
+			content: "services:\n  web:\n    image: nginx\n    volumes: [a:b:c:d]\n",
+			wantErr: "invalid volume specification",
+		},
+		{
+			name:    "port without target",
+			content: "services:\n  web:\n    image: nginx\n    ports:\n      - published: 8080\n",
+			wantErr: "port target is required",
+		},
+		{
+			name:    "invalid depends_on",
+			content: "services:\n  web:\n    image: nginx\n    depends_on: db\n",
+			wantErr: "expected a list or a mapping of services",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := Parse([]byte(tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			tt.check(t, file)
+		})
+	}
+}
+
+func TestValidateProjectName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"web", true},
+		{"my-app_2", true},
+		{"0day", true},
+		{"", false},
+		{"-web", false},
+		{"Web", false},
+		{"../etc", false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateProjectName(tt.name); (err == nil) != tt.valid {
+			t.Errorf("ValidateProjectName(%q) error = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package stacks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// composeFileName is the file name used for stored compose documents
+const composeFileName = "docker-compose.yml"
+
+// ErrStackNotFound is returned when a stack does not exist in the store
+var ErrStackNotFound = errors.New("stack not found")
+
+// StackInfo describes a stored stack
+type StackInfo struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// Store persists compose documents on disk, one directory per stack
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the directory of a stack, used to resolve relative bind mounts
+func (s *Store) Dir(name string) (string, error) {
+	dir, err := filepath.Abs(filepath.Join(s.dir, name))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve stack directory: %w", err)
+	}
+	return dir, nil
+}
+
+// List returns all stored stacks sorted by name
+func (s *Store) List() ([]StackInfo, error) {
+	// Initialize as empty slice to ensure JSON marshals to [] instead of null
+	result := []StackInfo{}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("failed to read stacks directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || ValidateProjectName(entry.Name()) != nil {
+			continue
+		}
+		path := filepath.Join(s.dir, entry.Name(), composeFileName)
+		stat, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		result = append(result, StackInfo{
+			Name:      entry.Name(),
+			Path:      path,
+			UpdatedAt: stat.ModTime().UTC().Format(time.RFC3339),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// Load reads the compose document of a stack
+func (s *Store) Load(name string) ([]byte, error) {
+	if err := ValidateProjectName(name); err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(s.dir, name, composeFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrStackNotFound
+		}
+		return nil, fmt.Errorf("failed to read stack: %w", err)
+	}
+	return content, nil
+}
+
+// Save writes the compose document of a stack, creating its directory if needed
+func (s *Store) Save(name string, content []byte) error {
+	if err := ValidateProjectName(name); err != nil {
+		return err
+	}
+
+	dir := filepath.Join(s.dir, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create stack directory: %w", err)
+	}
+
+	// Write to a temporary file first so a failed write never leaves a truncated document
+	tmp := filepath.Join(dir, "."+composeFileName+".tmp")
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return fmt.Errorf("failed to write stack: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, composeFileName)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write stack: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the compose document of a stack. Other files in the stack
+// directory (e.g. bind-mounted data) are left in place.
+func (s *Store) Delete(name string) error {
+	if err := ValidateProjectName(name); err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(s.dir, name, composeFileName)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrStackNotFound
+		}
+		return fmt.Errorf("failed to delete stack: %w", err)
+	}
+
+	// Remove the directory only if nothing else is in it
+	os.Remove(filepath.Join(s.dir, name))
+	return nil
+}